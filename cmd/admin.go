@@ -0,0 +1,199 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cilium/github-actions/pkg/github"
+	"goji.io"
+	"goji.io/pat"
+)
+
+// adminDeliveryID is recorded in the audit log as the delivery ID of the
+// mutations requested through the admin API.
+const adminDeliveryID = "admin-api"
+
+// adminAPI serves the endpoints that let maintainers inspect and re-run the
+// evaluation of a PR. All requests must carry the admin token as a bearer
+// token.
+type adminAPI struct {
+	handler *PRCommentHandler
+	token   string
+}
+
+// register registers the endpoints of the admin API, and the endpoint of the
+// audit log if the handler has one, in the given mux.
+func (a *adminAPI) register(mux *goji.Mux) {
+	mux.HandleFunc(pat.Get("/api/v1/:owner/:repo/pulls/:number/explain"), a.authenticated(a.explain))
+	mux.HandleFunc(pat.Post("/api/v1/:owner/:repo/pulls/:number/reevaluate"), a.authenticated(a.reevaluate))
+	if a.handler.auditLog != nil {
+		mux.HandleFunc(pat.Get("/audit"), a.authenticated(auditHandler(a.handler.auditLog)))
+	}
+}
+
+// authenticated wraps the given handler so that it is only called for
+// requests carrying the admin token.
+func (a *adminAPI) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// prRequest is a request about a PR of the admin API.
+type prRequest struct {
+	ghClient       *github.Client
+	installationID int64
+	owner          string
+	repoName       string
+	number         int
+	cfg            *github.PRBlockerConfig
+	cfgPath        string
+}
+
+// parsePRRequest creates the client of the installation of the repository of
+// the request and loads the config of the PR. It writes the HTTP error to 'w'
+// and returns nil if it fails.
+func (a *adminAPI) parsePRRequest(w http.ResponseWriter, r *http.Request) *prRequest {
+	owner, repoName := pat.Param(r, "owner"), pat.Param(r, "repo")
+	number, err := strconv.Atoi(pat.Param(r, "number"))
+	if err != nil {
+		http.Error(w, "PR number must be a number", http.StatusBadRequest)
+		return nil
+	}
+	log := logger.With().Str("owner", owner).Str("repo", repoName).Int("pr-number", number).Logger()
+	ctx := context.WithValue(log.WithContext(r.Context()), deliveryIDKey{}, adminDeliveryID)
+
+	appClient, err := a.handler.NewAppClient()
+	if err != nil {
+		log.Err(err).Msg("Unable to create app client")
+		http.Error(w, "unable to create app client", http.StatusInternalServerError)
+		return nil
+	}
+	installation, _, err := appClient.Apps.FindRepositoryInstallation(ctx, owner, repoName)
+	if err != nil {
+		if github.IsNotFound(err) {
+			http.Error(w, "app is not installed in the repository", http.StatusNotFound)
+			return nil
+		}
+		log.Err(err).Msg("Unable to find installation")
+		http.Error(w, "unable to find installation", http.StatusInternalServerError)
+		return nil
+	}
+	ghClient, err := a.handler.newClient(ctx, installation.GetID(), owner, repoName)
+	if err != nil {
+		log.Err(err).Msg("Unable to create client")
+		http.Error(w, "unable to create client", http.StatusInternalServerError)
+		return nil
+	}
+
+	pr, _, err := ghClient.GHClient.PullRequests.Get(ctx, owner, repoName, number)
+	if err != nil {
+		if github.IsNotFound(err) {
+			http.Error(w, "PR not found", http.StatusNotFound)
+			return nil
+		}
+		log.Err(err).Msg("Unable to get PR")
+		http.Error(w, "unable to get PR", http.StatusInternalServerError)
+		return nil
+	}
+	cfgPath, cfg, err := loadRepoConfigPath(ghClient, owner, repoName, pr.GetBase().GetSHA())
+	if err != nil {
+		log.Err(err).Msg("Unable to load config")
+		http.Error(w, fmt.Sprintf("unable to load config: %s", err), http.StatusInternalServerError)
+		return nil
+	}
+	if cfg == nil {
+		http.Error(w, "repository does not have a config", http.StatusNotFound)
+		return nil
+	}
+	ghClient.SetShadowMode(a.handler.shadowMode(*cfg))
+	return &prRequest{
+		ghClient:       ghClient,
+		installationID: installation.GetID(),
+		owner:          owner,
+		repoName:       repoName,
+		number:         number,
+		cfg:            cfg,
+		cfgPath:        cfgPath,
+	}
+}
+
+// explain serves the outcome of the evaluation of all rules of the config for
+// the PR, along with the changes the evaluation would make, without making
+// them.
+func (a *adminAPI) explain(w http.ResponseWriter, r *http.Request) {
+	pr := a.parsePRRequest(w, r)
+	if pr == nil {
+		return
+	}
+	// Nothing is changed so there is nothing to audit, and the evaluation
+	// is not a decision of the bot.
+	pr.ghClient.SetAuditLog(nil, "")
+	pr.ghClient.SetMetrics(nil)
+
+	e, err := pr.ghClient.ExplainPR(*pr.cfg, pr.cfgPath, pr.number)
+	if err != nil {
+		logger.Err(err).Int("pr-number", pr.number).Msg("Unable to explain PR")
+		http.Error(w, fmt.Sprintf("unable to explain PR: %s", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(e); err != nil {
+		logger.Err(err).Msg("Unable to write explanation")
+	}
+}
+
+// reevaluate queues the full evaluation of the PR, as the webhook handlers
+// run it, in order with the other work of the PR. The evaluation is work of
+// its own, with a client of its own, as the request is done once it is queued.
+func (a *adminAPI) reevaluate(w http.ResponseWriter, r *http.Request) {
+	pr := a.parsePRRequest(w, r)
+	if pr == nil {
+		return
+	}
+	key := prWorkKey(pr.owner+"/"+pr.repoName, pr.number)
+	id := fmt.Sprintf("admin:%s:%d", key, time.Now().UnixNano())
+	log := logger.With().Str("owner", pr.owner).Str("repo", pr.repoName).Str("delivery-id", id).Logger()
+	ctx := context.WithValue(log.WithContext(context.Background()), deliveryIDKey{}, id)
+	shadow := pr.ghClient.ShadowMode()
+	err := a.handler.queue.schedule(ctx, key, id, "", func() error {
+		c, err := a.handler.newClient(ctx, pr.installationID, pr.owner, pr.repoName)
+		if err != nil {
+			return err
+		}
+		c.SetShadowMode(shadow)
+		return c.ReevaluatePR(*pr.cfg, pr.number)
+	}, nil)
+	if err != nil {
+		log.Err(err).Int("pr-number", pr.number).Msg("Unable to queue re-evaluation of PR")
+		http.Error(w, fmt.Sprintf("unable to queue re-evaluation of PR: %s", err), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
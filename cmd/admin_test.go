@@ -0,0 +1,189 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cilium/github-actions/pkg/github"
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/palantir/go-githubapp/githubapp"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"goji.io"
+)
+
+func TestAdminAPI(t *testing.T) {
+	srv := githubtest.NewServer()
+	defer srv.Close()
+
+	t.Setenv("CONFIG_PATHS", ".github/maintainers-little-helper.yaml")
+	srv.SetFile("cilium", "cilium", ".github/maintainers-little-helper.yaml", testConfig)
+	srv.AddPR("cilium", "cilium", githubtest.PR{
+		Number: 1,
+		Author: "alice",
+		Commits: []githubtest.Commit{{
+			SHA:     "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+			Message: "Fix the bug\n\nSigned-off-by: Alice <alice@example.com>",
+			Author:  "alice",
+		}},
+	})
+	srv.AddPR("cilium", "tetragon", githubtest.PR{
+		Number:  1,
+		Author:  "alice",
+		Commits: []githubtest.Commit{{SHA: "abc", Author: "alice"}},
+	})
+
+	auditLog, err := github.OpenAuditLog(filepath.Join(t.TempDir(), "audit.log"))
+	assert.NoError(t, err)
+	defer auditLog.Close()
+	dt := newDeliveryTracker(time.Hour)
+	h := &PRCommentHandler{
+		ClientCreator: githubapp.NewClientCreator(srv.URL, srv.URL+"/graphql", githubtest.AppID, githubtest.PrivateKey()),
+		appID:         githubtest.AppID,
+		deliveries:    dt,
+		auditLog:      auditLog,
+	}
+	h.queue = newWorkQueue(h, dt, 2, 10, metrics.NewRegistry())
+	mux := goji.NewMux()
+	(&adminAPI{handler: h, token: "secret"}).register(mux)
+
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "explain without token",
+			method:     http.MethodGet,
+			path:       "/api/v1/cilium/cilium/pulls/1/explain",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "explain with wrong token",
+			method:     http.MethodGet,
+			path:       "/api/v1/cilium/cilium/pulls/1/explain",
+			token:      "secrets",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "reevaluate without token",
+			method:     http.MethodPost,
+			path:       "/api/v1/cilium/cilium/pulls/1/reevaluate",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "audit without token",
+			method:     http.MethodGet,
+			path:       "/audit?repo=cilium/cilium",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "explain",
+			method:     http.MethodGet,
+			path:       "/api/v1/cilium/cilium/pulls/1/explain",
+			token:      "secret",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"rule":"block-pr-with","passed":false}`,
+		},
+		{
+			name:       "explain with invalid PR number",
+			method:     http.MethodGet,
+			path:       "/api/v1/cilium/cilium/pulls/cilium/explain",
+			token:      "secret",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "explain unknown PR",
+			method:     http.MethodGet,
+			path:       "/api/v1/cilium/cilium/pulls/2/explain",
+			token:      "secret",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "PR not found",
+		},
+		{
+			name:       "explain PR of repository without config",
+			method:     http.MethodGet,
+			path:       "/api/v1/cilium/tetragon/pulls/1/explain",
+			token:      "secret",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "repository does not have a config",
+		},
+		{
+			name:       "reevaluate with wrong method",
+			method:     http.MethodGet,
+			path:       "/api/v1/cilium/cilium/pulls/1/reevaluate",
+			token:      "secret",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "audit",
+			method:     http.MethodGet,
+			path:       "/audit?repo=cilium/cilium",
+			token:      "secret",
+			wantStatus: http.StatusOK,
+			wantBody:   "[]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.method, tt.path, tt.token)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+	// Explaining the PR does not change it.
+	assert.Empty(t, srv.Labels("cilium", "cilium", 1))
+
+	// The re-evaluation is queued as work of its own, keyed by the PR.
+	rec := serve(http.MethodPost, "/api/v1/cilium/cilium/pulls/1/reevaluate", "secret")
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	drainQueue(t, h.queue)
+	assert.Contains(t, srv.Labels("cilium", "cilium", 1), "dont-merge/needs-release-note")
+
+	records, err := auditLog.Query(context.Background(), "cilium", "cilium", 1)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, records) {
+		for _, r := range records {
+			assert.True(t, strings.HasPrefix(r.DeliveryID, "admin:cilium/cilium#1:"), r.DeliveryID)
+		}
+	}
+	assert.Empty(t, srv.Unhandled())
+
+	// The audit log is only served if there is one.
+	h.auditLog = nil
+	mux = goji.NewMux()
+	(&adminAPI{handler: h, token: "secret"}).register(mux)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/audit?repo=cilium/cilium", "secret").Code)
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cilium/github-actions/pkg/github"
+)
+
+// auditHandler serves the records of the audit log of a repository, e.g.
+// "/audit?repo=cilium/cilium&pr=123". The "pr" parameter is optional.
+func auditHandler(l *github.AuditLog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner, repoName, ok := strings.Cut(r.URL.Query().Get("repo"), "/")
+		if !ok || owner == "" || repoName == "" {
+			http.Error(w, `"repo" must be set to "owner/name"`, http.StatusBadRequest)
+			return
+		}
+		var number int
+		if pr := r.URL.Query().Get("pr"); pr != "" {
+			var err error
+			number, err = strconv.Atoi(pr)
+			if err != nil {
+				http.Error(w, `"pr" must be a number`, http.StatusBadRequest)
+				return
+			}
+		}
+
+		records, err := l.Query(r.Context(), owner, repoName, number)
+		if err != nil {
+			logger.Err(err).Msg("Unable to query audit log")
+			http.Error(w, "unable to query audit log", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(records); err != nil {
+			logger.Err(err).Msg("Unable to write audit records")
+		}
+	}
+}
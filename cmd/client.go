@@ -16,12 +16,14 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/signal"
 	"regexp"
+	"testing"
 
 	"github.com/cilium/github-actions/pkg/github"
 	"github.com/cilium/github-actions/pkg/jenkins"
@@ -37,6 +39,7 @@ var (
 	clientMode bool
 	config     string
 	baseBranch string
+	shadowMode bool
 )
 
 func init() {
@@ -46,6 +49,11 @@ func init() {
 	flag.StringVar(&baseBranch, "branch", "main", "Base branch name (for client-mode)")
 	flag.StringVar(&config, "config", "", "Flake config file (for client-mode)")
 	flag.BoolVar(&clientMode, "client-mode", false, "Runs MLH in client mode (useful for development)")
+	flag.BoolVar(&shadowMode, "shadow", false, "Log the changes MLH would make in GitHub instead of making them")
+	// The test binary parses its own flags, which are not defined yet.
+	if testing.Testing() {
+		return
+	}
 	flag.Parse()
 
 	go signals()
@@ -99,6 +107,10 @@ func runClient() {
 	} else {
 		fmt.Printf("Getting PRs from GH\n")
 		ghClient = github.NewClient(os.Getenv("GITHUB_TOKEN"), orgName, repoName, zerolog.Ctx(globalCtx))
+		ghClient.SetShadowMode(shadowMode)
+		if shadowMode {
+			defer printPlannedActions(ghClient)
+		}
 
 		if prNumber != 0 {
 			err := ghClient.CommitContains(cfg.RequireMsgsInCommit, orgName, repoName, prNumber)
@@ -168,6 +180,20 @@ func runClient() {
 	}
 }
 
+// printPlannedActions prints the actions recorded by the client in shadow
+// mode, one JSON object per line.
+func printPlannedActions(ghClient *github.Client) {
+	actions := ghClient.PlannedActions()
+	fmt.Printf("%d planned actions in shadow mode\n", len(actions))
+	for _, pa := range actions {
+		b, err := json.Marshal(pa)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(b))
+	}
+}
+
 func loadConfig(cfgFile string) (*github.PRBlockerConfig, error) {
 	b, err := ioutil.ReadFile(cfgFile)
 	if err != nil {
@@ -0,0 +1,104 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/cilium/github-actions/pkg/github"
+	gh "github.com/google/go-github/v84/github"
+	"github.com/palantir/go-githubapp/githubapp"
+	"github.com/shurcooL/githubv4"
+)
+
+// installationClientCreator creates the clients of each installation with
+// their own rate limit middleware, as the middleware of go-githubapp does not
+// know which installation a request belongs to. All other clients are created
+// by the embedded ClientCreator.
+//
+// The REST clients of the installations skip the rate limit check of
+// go-github, so that requests wait for exhausted quotas to reset in the
+// middleware instead of failing right away.
+type installationClientCreator struct {
+	githubapp.ClientCreator
+
+	// newCreator returns a ClientCreator whose clients use the given
+	// middleware.
+	newCreator func(middleware githubapp.ClientMiddleware) (githubapp.ClientCreator, error)
+	limiter    *github.RateLimiter
+
+	mu sync.Mutex
+	// creators maps an installation ID to the ClientCreator of its clients.
+	creators map[int64]githubapp.ClientCreator
+	// clients maps an installation ID to its REST client, which is shared
+	// since it is configured once created.
+	clients map[int64]*gh.Client
+}
+
+func newInstallationClientCreator(limiter *github.RateLimiter, newCreator func(githubapp.ClientMiddleware) (githubapp.ClientCreator, error)) (*installationClientCreator, error) {
+	cc, err := newCreator(limiter.Middleware("app"))
+	if err != nil {
+		return nil, err
+	}
+	return &installationClientCreator{
+		ClientCreator: cc,
+		newCreator:    newCreator,
+		limiter:       limiter,
+		creators:      map[int64]githubapp.ClientCreator{},
+		clients:       map[int64]*gh.Client{},
+	}, nil
+}
+
+func (c *installationClientCreator) creator(installationID int64) (githubapp.ClientCreator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cc, ok := c.creators[installationID]; ok {
+		return cc, nil
+	}
+	cc, err := c.newCreator(c.limiter.Middleware(strconv.FormatInt(installationID, 10)))
+	if err != nil {
+		return nil, err
+	}
+	c.creators[installationID] = cc
+	return cc, nil
+}
+
+func (c *installationClientCreator) NewInstallationClient(installationID int64) (*gh.Client, error) {
+	cc, err := c.creator(installationID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[installationID]; ok {
+		return client, nil
+	}
+	client, err := cc.NewInstallationClient(installationID)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[installationID] = github.SkipRateLimitCheck(client)
+	return client, nil
+}
+
+func (c *installationClientCreator) NewInstallationV4Client(installationID int64) (*githubv4.Client, error) {
+	cc, err := c.creator(installationID)
+	if err != nil {
+		return nil, err
+	}
+	return cc.NewInstallationV4Client(installationID)
+}
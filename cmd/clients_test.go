@@ -0,0 +1,54 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/github"
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/palantir/go-githubapp/githubapp"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallationClientCreator(t *testing.T) {
+	srv := githubtest.NewServer()
+	defer srv.Close()
+
+	var middlewares int
+	cc, err := newInstallationClientCreator(github.NewRateLimiter(metrics.NewRegistry()), func(rateLimit githubapp.ClientMiddleware) (githubapp.ClientCreator, error) {
+		middlewares++
+		return githubapp.NewClientCreator(srv.URL, srv.URL+"/graphql", githubtest.AppID, githubtest.PrivateKey(),
+			githubapp.WithClientMiddleware(rateLimit),
+		), nil
+	})
+	assert.NoError(t, err)
+
+	client, err := cc.NewInstallationClient(1)
+	assert.NoError(t, err)
+	assert.True(t, client.DisableRateLimitCheck)
+	again, err := cc.NewInstallationClient(1)
+	assert.NoError(t, err)
+	assert.Same(t, client, again)
+
+	other, err := cc.NewInstallationClient(2)
+	assert.NoError(t, err)
+	assert.NotSame(t, client, other)
+	assert.True(t, other.DisableRateLimitCheck)
+
+	// The app and each installation have their own middleware.
+	assert.Equal(t, 3, middlewares)
+}
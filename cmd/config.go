@@ -15,6 +15,9 @@
 package main
 
 import (
+	"fmt"
+
+	"github.com/cilium/github-actions/pkg/github"
 	"github.com/palantir/go-baseapp/baseapp"
 	"github.com/palantir/go-githubapp/githubapp"
 )
@@ -23,3 +26,30 @@ type Config struct {
 	Server baseapp.HTTPConfig `yaml:"server"`
 	Github githubapp.Config   `yaml:"github"`
 }
+
+// loadRepoConfig returns the config of the repository at the given SHA, or nil
+// if the repository does not have a config. The config might enable shadow
+// mode, which callers must apply to the clients acting on the repository.
+func loadRepoConfig(ghClient *github.Client, owner, repoName, ghSha string) (*github.PRBlockerConfig, error) {
+	_, c, err := loadRepoConfigPath(ghClient, owner, repoName, ghSha)
+	return c, err
+}
+
+// loadRepoConfigPath is like loadRepoConfig but also returns the path of the
+// config.
+func loadRepoConfigPath(ghClient *github.Client, owner, repoName, ghSha string) (string, *github.PRBlockerConfig, error) {
+	return github.LoadConfig(ghClient, owner, repoName, ghSha)
+}
+
+// repoConfig is like loadRepoConfig but fails if the repository does not have
+// a config.
+func repoConfig(ghClient *github.Client, owner, repoName, ghSha string) (github.PRBlockerConfig, error) {
+	c, err := loadRepoConfig(ghClient, owner, repoName, ghSha)
+	if err != nil {
+		return github.PRBlockerConfig{}, err
+	}
+	if c == nil {
+		return github.PRBlockerConfig{}, fmt.Errorf("unable to find config files in sha %s", ghSha)
+	}
+	return *c, nil
+}
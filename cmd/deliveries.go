@@ -0,0 +1,408 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/palantir/go-githubapp/githubapp"
+)
+
+const (
+	// defaultRedeliverWindow is the default age of the oldest failed delivery
+	// that is redelivered. GitHub only allows redeliveries of the last 3
+	// days.
+	defaultRedeliverWindow = 6 * time.Hour
+	// maxRedeliveryAttempts is the number of times the same delivery is
+	// redelivered before giving up.
+	maxRedeliveryAttempts = 3
+)
+
+// The states of a delivery recorded in the journal of a deliveryTracker.
+const (
+	deliveryQueued      = "queued"
+	deliveryProcessed   = "processed"
+	deliveryFailed      = "failed"
+	deliveryRedelivered = "redelivered"
+)
+
+// deliveryRecord is a change of state of a delivery, as stored in the journal
+// of a deliveryTracker.
+type deliveryRecord struct {
+	GUID  string    `json:"guid"`
+	State string    `json:"state"`
+	Time  time.Time `json:"time"`
+}
+
+// deliveryTracker keeps track of the webhook deliveries that were already
+// processed, so that the same delivery is never handled twice, and of the
+// deliveries that were acknowledged but failed to be handled, so that they
+// can be redelivered.
+type deliveryTracker struct {
+	// ttl is the amount of time a delivery is remembered.
+	ttl time.Duration
+
+	mu sync.Mutex
+	// queued maps the GUID of the deliveries waiting to be handled to the
+	// time they were queued.
+	queued map[string]time.Time
+	// processed maps the GUID of the processed deliveries to the time they
+	// were processed.
+	processed map[string]time.Time
+	// failed maps the GUID of the deliveries that we gave up on to the time
+	// they failed. Deliveries still queued when we stopped are failed too.
+	failed map[string]time.Time
+	// redeliveries maps the GUID of a delivery to the number of times we
+	// requested it to be redelivered.
+	redeliveries map[string]int
+
+	// path is the path of the journal, if any, where each change of state is
+	// appended so that it survives restarts.
+	path    string
+	journal *os.File
+}
+
+func newDeliveryTracker(ttl time.Duration) *deliveryTracker {
+	return &deliveryTracker{
+		ttl:          ttl,
+		queued:       map[string]time.Time{},
+		processed:    map[string]time.Time{},
+		failed:       map[string]time.Time{},
+		redeliveries: map[string]int{},
+	}
+}
+
+// openDeliveryTracker returns a deliveryTracker persisted in the journal
+// stored in the given file, creating it if it does not exist. The deliveries
+// recorded in the journal are restored, and those that were still queued are
+// considered failed since they were lost when we stopped.
+func openDeliveryTracker(path string, ttl time.Duration) (*deliveryTracker, error) {
+	t := newDeliveryTracker(ttl)
+	f, err := os.Open(path)
+	switch {
+	case err == nil:
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var r deliveryRecord
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				// The last record is truncated if we stopped while writing it.
+				logger.Err(err).Str("path", path).Msg("Ignoring invalid delivery record")
+				continue
+			}
+			t.apply(r)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("unable to read delivery log: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("unable to open delivery log: %w", err)
+	}
+
+	for guid := range t.queued {
+		t.apply(deliveryRecord{GUID: guid, State: deliveryFailed, Time: time.Now()})
+	}
+
+	t.path = path
+	// Rewrite the journal, which also drops any truncated record, before
+	// appending to it.
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.compact(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// apply applies the change of state of the given record. t.mu must be held.
+func (t *deliveryTracker) apply(r deliveryRecord) {
+	switch r.State {
+	case deliveryQueued:
+		t.queued[r.GUID] = r.Time
+		delete(t.processed, r.GUID)
+		delete(t.failed, r.GUID)
+	case deliveryProcessed:
+		t.processed[r.GUID] = r.Time
+		delete(t.queued, r.GUID)
+		delete(t.failed, r.GUID)
+		delete(t.redeliveries, r.GUID)
+	case deliveryFailed:
+		t.failed[r.GUID] = r.Time
+		delete(t.queued, r.GUID)
+		delete(t.processed, r.GUID)
+	case deliveryRedelivered:
+		t.redeliveries[r.GUID]++
+	}
+}
+
+// record applies the change of state of the given delivery and appends it
+// to the journal, if any. t.mu must be held.
+func (t *deliveryTracker) record(guid, state string) {
+	r := deliveryRecord{GUID: guid, State: state, Time: time.Now()}
+	t.apply(r)
+	if t.journal == nil {
+		return
+	}
+	b, err := json.Marshal(r)
+	if err == nil {
+		_, err = t.journal.Write(append(b, '\n'))
+	}
+	if err != nil {
+		logger.Err(err).Str("guid", guid).Str("state", state).Msg("Unable to record webhook delivery")
+	}
+}
+
+// compact replaces the journal with the records of the current state of the
+// deliveries. t.mu must be held.
+func (t *deliveryTracker) compact() error {
+	var b []byte
+	for state, deliveries := range map[string]map[string]time.Time{
+		deliveryQueued:    t.queued,
+		deliveryProcessed: t.processed,
+		deliveryFailed:    t.failed,
+	} {
+		for guid, at := range deliveries {
+			r, err := json.Marshal(deliveryRecord{GUID: guid, State: state, Time: at})
+			if err != nil {
+				return err
+			}
+			b = append(append(b, r...), '\n')
+		}
+	}
+	now := time.Now()
+	for guid, attempts := range t.redeliveries {
+		r, err := json.Marshal(deliveryRecord{GUID: guid, State: deliveryRedelivered, Time: now})
+		if err != nil {
+			return err
+		}
+		for range attempts {
+			b = append(append(b, r...), '\n')
+		}
+	}
+
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("unable to write delivery log: %w", err)
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		return fmt.Errorf("unable to write delivery log: %w", err)
+	}
+	f, err := os.OpenFile(t.path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open delivery log: %w", err)
+	}
+	if t.journal != nil {
+		t.journal.Close()
+	}
+	t.journal = f
+	return nil
+}
+
+// isProcessed returns true if the delivery with the given GUID was already
+// processed.
+func (t *deliveryTracker) isProcessed(guid string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.processed[guid]
+	return ok
+}
+
+// isFailed returns true if the delivery with the given GUID was acknowledged
+// but failed to be handled.
+func (t *deliveryTracker) isFailed(guid string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.failed[guid]
+	return ok
+}
+
+// markQueued records that the delivery with the given GUID was acknowledged
+// and is waiting to be handled, even if it was processed before.
+func (t *deliveryTracker) markQueued(guid string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(guid, deliveryQueued)
+}
+
+// markFailed records that we gave up on handling the delivery with the given
+// GUID, even if it was processed before.
+func (t *deliveryTracker) markFailed(guid string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(guid, deliveryFailed)
+}
+
+// markProcessed records that the delivery with the given GUID was processed.
+func (t *deliveryTracker) markProcessed(guid string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(guid, deliveryProcessed)
+}
+
+// shouldRedeliver returns true, and records a new redelivery attempt, if the
+// failed delivery with the given GUID was neither processed nor redelivered
+// too many times already.
+func (t *deliveryTracker) shouldRedeliver(guid string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.processed[guid]; ok {
+		return false
+	}
+	if t.redeliveries[guid] >= maxRedeliveryAttempts {
+		return false
+	}
+	t.record(guid, deliveryRedelivered)
+	return true
+}
+
+// prune forgets the deliveries queued, processed or failed more than ttl
+// ago, and removes them from the journal, if any.
+func (t *deliveryTracker) prune() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, deliveries := range []map[string]time.Time{t.queued, t.processed, t.failed} {
+		for guid, at := range deliveries {
+			if time.Since(at) > t.ttl {
+				delete(deliveries, guid)
+			}
+		}
+	}
+	if t.journal == nil {
+		return
+	}
+	if err := t.compact(); err != nil {
+		logger.Err(err).Str("path", t.path).Msg("Unable to compact delivery log")
+	}
+}
+
+// close closes the journal, if any.
+func (t *deliveryTracker) close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.journal == nil {
+		return nil
+	}
+	err := t.journal.Close()
+	t.journal = nil
+	return err
+}
+
+// redeliverer requests GitHub to redeliver the webhook deliveries of the app
+// that failed, e.g., while the server was down.
+type redeliverer struct {
+	cc         githubapp.ClientCreator
+	deliveries *deliveryTracker
+	interval   time.Duration
+	// window is the age of the oldest failed delivery that is redelivered.
+	window time.Duration
+}
+
+// run redelivers the failed deliveries right away and then every interval
+// until the context is done.
+func (r *redeliverer) run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.redeliver(ctx)
+		r.deliveries.prune()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// redeliver requests the redelivery of all deliveries within the window that
+// failed and that were not processed since.
+func (r *redeliverer) redeliver(ctx context.Context) {
+	appClient, err := r.cc.NewAppClient()
+	if err != nil {
+		logger.Err(err).Msg("Unable to create app client")
+		return
+	}
+
+	since := time.Now().Add(-r.window)
+	var deliveries []*gh.HookDelivery
+	opts := &gh.ListCursorOptions{PerPage: 100}
+	for {
+		page, resp, err := appClient.Apps.ListHookDeliveries(ctx, opts)
+		if err != nil {
+			logger.Err(err).Msg("Unable to list webhook deliveries")
+			return
+		}
+		deliveries = append(deliveries, page...)
+		// Deliveries are listed from the most to the least recent.
+		if len(page) == 0 || page[len(page)-1].GetDeliveredAt().Before(since) || resp.Cursor == "" {
+			break
+		}
+		opts.Cursor = resp.Cursor
+	}
+
+	var redelivered int
+	for guid, id := range failedDeliveries(deliveries, since, r.deliveries.isFailed) {
+		if !r.deliveries.shouldRedeliver(guid) {
+			continue
+		}
+		_, _, err := appClient.Apps.RedeliverHookDelivery(ctx, id)
+		// RedeliverHookDelivery returns an AcceptedError on success.
+		if _, ok := err.(*gh.AcceptedError); err != nil && !ok {
+			logger.Err(err).Str("guid", guid).Msg("Unable to redeliver webhook delivery")
+			continue
+		}
+		redelivered++
+	}
+	logger.Info().Fields(map[string]interface{}{
+		"deliveries":  len(deliveries),
+		"redelivered": redelivered,
+	}).Msg("Redelivered failed webhook deliveries")
+}
+
+// failedDeliveries returns the ID of the most recent attempt of each delivery,
+// by GUID, delivered after 'since' that never succeeded, or that we
+// acknowledged but failed to handle according to 'failed'.
+func failedDeliveries(deliveries []*gh.HookDelivery, since time.Time, failed func(guid string) bool) map[string]int64 {
+	var (
+		succeeded = map[string]struct{}{}
+		latest    = map[string]*gh.HookDelivery{}
+	)
+	for _, d := range deliveries {
+		if d.GetDeliveredAt().Before(since) {
+			continue
+		}
+		guid := d.GetGUID()
+		if code := d.GetStatusCode(); code >= 200 && code < 300 {
+			succeeded[guid] = struct{}{}
+		}
+		if prev, ok := latest[guid]; !ok || d.GetDeliveredAt().After(prev.GetDeliveredAt().Time) {
+			latest[guid] = d
+		}
+	}
+
+	ids := map[string]int64{}
+	for guid, d := range latest {
+		if _, ok := succeeded[guid]; !ok || failed(guid) {
+			ids[guid] = d.GetID()
+		}
+	}
+	return ids
+}
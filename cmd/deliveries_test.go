@@ -0,0 +1,137 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryTracker(t *testing.T) {
+	dt := newDeliveryTracker(time.Hour)
+
+	assert.False(t, dt.isProcessed("a"))
+	dt.markProcessed("a")
+	assert.True(t, dt.isProcessed("a"))
+	assert.False(t, dt.shouldRedeliver("a"), "processed deliveries are never redelivered")
+
+	for range maxRedeliveryAttempts {
+		assert.True(t, dt.shouldRedeliver("b"))
+	}
+	assert.False(t, dt.shouldRedeliver("b"), "too many redeliveries")
+
+	// Processed deliveries are forgotten once they expire.
+	dt.processed["a"] = time.Now().Add(-2 * time.Hour)
+	dt.prune()
+	assert.False(t, dt.isProcessed("a"))
+
+	// Deliveries are failed until they are processed.
+	dt.markQueued("c")
+	assert.False(t, dt.isFailed("c"))
+	dt.markFailed("c")
+	assert.True(t, dt.isFailed("c"))
+	dt.markQueued("c")
+	assert.False(t, dt.isFailed("c"))
+	dt.markProcessed("c")
+	assert.False(t, dt.isFailed("c"))
+	assert.True(t, dt.isProcessed("c"))
+
+	// Processed deliveries are failed if their evaluations fail afterwards.
+	dt.markFailed("c")
+	assert.True(t, dt.isFailed("c"))
+	assert.False(t, dt.isProcessed("c"))
+}
+
+func TestOpenDeliveryTracker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deliveries.log")
+
+	dt, err := openDeliveryTracker(path, time.Hour)
+	assert.NoError(t, err)
+	dt.markProcessed("a")
+	dt.markProcessed("expired")
+	assert.True(t, dt.shouldRedeliver("b"))
+	assert.True(t, dt.shouldRedeliver("b"))
+	dt.markQueued("queued")
+	assert.NoError(t, dt.close())
+
+	// Simulate a record truncated by a crash, and a delivery that expired
+	// while we were down.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"guid":"expired","state":"processed","time":"2020-01-01T00:00:00Z"}` + "\n" + `{"guid":"c","sta`)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	dt, err = openDeliveryTracker(path, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, dt.isProcessed("a"))
+	assert.True(t, dt.shouldRedeliver("b"))
+	assert.False(t, dt.shouldRedeliver("b"), "redelivery attempts are restored")
+	assert.False(t, dt.isProcessed("c"))
+	assert.True(t, dt.isFailed("queued"), "deliveries still queued when we stopped are failed")
+	dt.prune()
+	assert.False(t, dt.isProcessed("expired"))
+	assert.NoError(t, dt.close())
+
+	// The journal is compacted when pruned.
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2+maxRedeliveryAttempts, strings.Count(string(b), "\n"))
+	assert.NotContains(t, string(b), "expired")
+
+	dt, err = openDeliveryTracker(path, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, dt.isProcessed("a"))
+	assert.False(t, dt.shouldRedeliver("b"))
+	assert.NoError(t, dt.close())
+}
+
+func Test_failedDeliveries(t *testing.T) {
+	now := time.Now()
+	delivery := func(id int64, guid string, age time.Duration, statusCode int) *gh.HookDelivery {
+		return &gh.HookDelivery{
+			ID:          new(id),
+			GUID:        new(guid),
+			DeliveredAt: &gh.Timestamp{Time: now.Add(-age)},
+			StatusCode:  new(statusCode),
+		}
+	}
+	deliveries := []*gh.HookDelivery{
+		// Most recent first, as listed by GitHub.
+		delivery(1, "ok", time.Minute, 200),
+		delivery(2, "retried", 2*time.Minute, 202),
+		delivery(3, "failed-twice", 3*time.Minute, 502),
+		delivery(4, "retried", 4*time.Minute, 500),
+		delivery(5, "failed-twice", 5*time.Minute, 500),
+		delivery(6, "timed-out", 6*time.Minute, 0),
+		delivery(7, "too-old", 2*time.Hour, 500),
+		delivery(8, "failed-locally", 7*time.Minute, 202),
+	}
+
+	got := failedDeliveries(deliveries, now.Add(-time.Hour), func(guid string) bool {
+		return guid == "failed-locally"
+	})
+	assert.Equal(t, map[string]int64{
+		"failed-twice":   3,
+		"timed-out":      6,
+		"failed-locally": 8,
+	}, got)
+}
@@ -19,75 +19,213 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/cilium/github-actions/pkg/github"
 	gh "github.com/google/go-github/v84/github"
 	"github.com/palantir/go-githubapp/githubapp"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
-	"gopkg.in/yaml.v2"
 )
 
+// deliveryIDKey is the context key of the ID of the webhook delivery being
+// handled.
+type deliveryIDKey struct{}
+
 type PRCommentHandler struct {
 	githubapp.ClientCreator
+
+	// appID is the ID of the GitHub App the handler authenticates as.
+	appID int64
+
+	// teamCacheTTL is the amount of time the members of a team are cached.
+	teamCacheTTL time.Duration
+
+	teamCachesMu sync.Mutex
+	// teamCaches maps an installation ID to its cache of team members.
+	teamCaches map[int64]*github.TeamMembersCache
+
+	// deliveries tracks the webhook deliveries already processed.
+	deliveries *deliveryTracker
+
+	// auditLog, if set, records all mutations issued by the clients.
+	auditLog *github.AuditLog
+
+	// shadow makes all clients record the changes they would make instead
+	// of making them.
+	shadow bool
+
+	// ciDebouncer, if set, collapses the auto-merge evaluations triggered by
+	// bursts of status and check run events.
+	ciDebouncer *github.CIDebouncer
+
+	// metrics, if set, records the decisions of the clients. The events
+	// handled are recorded by the queue, once per delivery.
+	metrics *github.Metrics
+
+	// queue, if set, is where the clients schedule the evaluations of PRs
+	// triggered by events that are not tied to a single PR.
+	queue *workQueue
 }
 
 func (h *PRCommentHandler) Handles() []string {
-	return []string{"pull_request", "pull_request_review", "status", "check_run", "issue_comment"}
+	return []string{"pull_request", "pull_request_review", "pull_request_review_thread", "status", "check_run", "check_suite", "issue_comment", "merge_group"}
 }
 
 func (h *PRCommentHandler) Handle(ctx context.Context, eventType, deliveryID string, payload []byte) error {
+	if h.deliveries != nil && h.deliveries.isProcessed(deliveryID) {
+		zerolog.Ctx(ctx).Info().Str("delivery-id", deliveryID).Msg("Ignoring delivery already processed")
+		return nil
+	}
+	ctx = context.WithValue(ctx, deliveryIDKey{}, deliveryID)
+
 	var err error
 	switch eventType {
 	case "status":
 		err = h.HandleStatusEvent(ctx, payload)
 	case "check_run":
 		err = h.HandleCheckRunEvent(ctx, payload)
+	case "check_suite":
+		err = h.HandleCheckSuiteEvent(ctx, payload)
 	case "pull_request_review":
 		err = h.HandlePullRequestReviewEvent(ctx, payload)
+	case "pull_request_review_thread":
+		err = h.HandlePullRequestReviewThreadEvent(ctx, payload)
 	case "pull_request":
 		err = h.HandlePullRequestEvent(ctx, payload)
 	case "issue_comment":
 		err = h.HandleIssueCommentEvent(ctx, payload)
+	case "merge_group":
+		err = h.HandleMergeGroupEvent(ctx, payload)
 	}
 	if err != nil {
 		logger.Err(err).Msg("Unable to handle event")
-		return fmt.Errorf("unable to handle event: %s\n", err)
+		return fmt.Errorf("unable to handle event: %w", err)
 	}
 
+	if h.deliveries != nil {
+		h.deliveries.markProcessed(deliveryID)
+	}
 	return nil
 }
 
-func (h *PRCommentHandler) HandlePullRequestEvent(ctx context.Context, payload []byte) error {
-	var event gh.PullRequestEvent
-	if err := json.Unmarshal(payload, &event); err != nil {
-		return errors.Wrap(err, "failed to parse pull request event payload")
+// eventAction returns the action of the given webhook payload, or "none" if
+// the event does not have actions.
+func eventAction(payload []byte) string {
+	var event struct {
+		Action string `json:"action"`
 	}
-	installationID := event.GetInstallation().GetID()
+	if err := json.Unmarshal(payload, &event); err != nil || event.Action == "" {
+		return "none"
+	}
+	return event.Action
+}
 
+// newClient returns a github.Client authenticated as the given installation
+// for both the REST and GraphQL APIs.
+func (h *PRCommentHandler) newClient(ctx context.Context, installationID int64, owner, repoName string) (*github.Client, error) {
 	installClient, err := h.NewInstallationClient(installationID)
 	if err != nil {
+		return nil, err
+	}
+	installV4Client, err := h.NewInstallationV4Client(installationID)
+	if err != nil {
+		return nil, err
+	}
+	ghClient := github.NewClientFromGHClient(installClient, installV4Client, owner, repoName, zerolog.Ctx(ctx))
+	ghClient.SetAppID(h.appID)
+	ghClient.SetTeamMembersCache(h.teamMembersCache(installationID))
+	ghClient.SetCIDebouncer(h.ciDebouncer)
+	ghClient.SetMetrics(h.metrics)
+	ghClient.SetShadowMode(h.shadow)
+	if h.auditLog != nil {
+		deliveryID, _ := ctx.Value(deliveryIDKey{}).(string)
+		ghClient.SetAuditLog(h.auditLog, deliveryID)
+	}
+	if h.queue != nil {
+		ghClient.SetScheduler(h.scheduler(ctx, installationID, ghClient, owner, repoName))
+	}
+	return ghClient, nil
+}
+
+// scheduler returns the scheduler of the given client, which queues the
+// evaluations of PRs under the key of the PR. The evaluations are part of the
+// delivery being handled, unless detached, in which case they are synthetic
+// work of their own. Either way, each evaluation gets a client of its own, in
+// the same mode as the given client.
+func (h *PRCommentHandler) scheduler(ctx context.Context, installationID int64, ghClient *github.Client, owner, repoName string) github.Scheduler {
+	deliveryID, _ := ctx.Value(deliveryIDKey{}).(string)
+	return func(prNumber int, detached bool, eval func(c *github.Client) error) error {
+		key := prWorkKey(owner+"/"+repoName, prNumber)
+		id, source, evalCtx := deliveryID+":evaluate:"+key, deliveryID, ctx
+		if detached {
+			id, source = fmt.Sprintf("debounce:%s:%d", key, time.Now().UnixNano()), ""
+			log := logger.With().Str("owner", owner).Str("repo", repoName).Str("delivery-id", id).Logger()
+			evalCtx = context.WithValue(log.WithContext(context.Background()), deliveryIDKey{}, id)
+		}
+		shadow := ghClient.ShadowMode()
+		err := h.queue.schedule(evalCtx, key, id, source, func() error {
+			c, err := h.newClient(evalCtx, installationID, owner, repoName)
+			if err != nil {
+				return err
+			}
+			c.SetShadowMode(shadow)
+			return eval(c)
+		}, nil)
+		if errors.Is(err, errAlreadyQueued) {
+			return nil
+		}
 		return err
 	}
+}
+
+// shadowMode returns true if the clients must only record the changes they
+// would make to the repository with the given config.
+func (h *PRCommentHandler) shadowMode(cfg github.PRBlockerConfig) bool {
+	return h.shadow || cfg.Mode == github.ModeShadow
+}
+
+// teamMembersCache returns the cache of team members of the given
+// installation.
+func (h *PRCommentHandler) teamMembersCache(installationID int64) *github.TeamMembersCache {
+	h.teamCachesMu.Lock()
+	defer h.teamCachesMu.Unlock()
+	if h.teamCaches == nil {
+		h.teamCaches = map[int64]*github.TeamMembersCache{}
+	}
+	tc, ok := h.teamCaches[installationID]
+	if !ok {
+		tc = github.NewTeamMembersCache(h.teamCacheTTL)
+		h.teamCaches[installationID] = tc
+	}
+	return tc
+}
+
+func (h *PRCommentHandler) HandlePullRequestEvent(ctx context.Context, payload []byte) error {
+	var event gh.PullRequestEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return errors.Wrap(err, "failed to parse pull request event payload")
+	}
+	installationID := event.GetInstallation().GetID()
 
 	owner := event.PullRequest.Base.Repo.GetOwner().GetLogin()
 	repoName := event.PullRequest.Base.Repo.GetName()
-	ghClient := github.NewClientFromGHClient(installClient, owner, repoName, zerolog.Ctx(ctx))
-	ghSha := event.PullRequest.Base.GetSHA()
-
-	actionCfgPath, cfgFile, err := github.GetActionsCfg(ghClient, owner, repoName, ghSha)
+	ghClient, err := h.newClient(ctx, installationID, owner, repoName)
 	if err != nil {
 		return err
 	}
-	if actionCfgPath == "" {
-		return fmt.Errorf("unable to find config files in sha %s", ghSha)
+	ghSha := event.PullRequest.Base.GetSHA()
+	if github.IsBaseRetargeted(&event) {
+		// Reload the config from the tip of the new base branch.
+		ghSha = event.PullRequest.Base.GetRef()
 	}
 
-	var c github.PRBlockerConfig
-	err = yaml.Unmarshal(cfgFile, &c)
+	c, err := repoConfig(ghClient, owner, repoName, ghSha)
 	if err != nil {
-		return fmt.Errorf("unable to unmarshal config %q file: %s\n", actionCfgPath, err)
+		return err
 	}
+	ghClient.SetShadowMode(h.shadowMode(c))
 
 	return ghClient.HandlePullRequestEvent(c, &event)
 }
@@ -99,29 +237,21 @@ func (h *PRCommentHandler) HandleStatusEvent(ctx context.Context, payload []byte
 	}
 	installationID := event.GetInstallation().GetID()
 
-	installClient, err := h.NewInstallationClient(installationID)
-	if err != nil {
-		return err
-	}
-
 	owner := event.Repo.GetOwner().GetLogin()
 	repoName := event.Repo.GetName()
-	ghClient := github.NewClientFromGHClient(installClient, owner, repoName, zerolog.Ctx(ctx))
-	ghSha := event.GetSHA()
-
-	actionCfgPath, cfgFile, err := github.GetActionsCfg(ghClient, owner, repoName, ghSha)
+	ghClient, err := h.newClient(ctx, installationID, owner, repoName)
 	if err != nil {
 		return err
 	}
-	if actionCfgPath == "" {
-		return fmt.Errorf("unable to find config files in sha %s", ghSha)
-	}
+	// The status might be of the head of a PR, which must not change the
+	// config it is evaluated with.
+	ghSha := event.Repo.GetDefaultBranch()
 
-	var c github.PRBlockerConfig
-	err = yaml.Unmarshal(cfgFile, &c)
+	c, err := repoConfig(ghClient, owner, repoName, ghSha)
 	if err != nil {
-		return fmt.Errorf("unable to unmarshal config %q file: %s\n", actionCfgPath, err)
+		return err
 	}
+	ghClient.SetShadowMode(h.shadowMode(c))
 
 	return ghClient.HandleStatusEvent(c, &event)
 }
@@ -133,31 +263,45 @@ func (h *PRCommentHandler) HandlePullRequestReviewEvent(ctx context.Context, pay
 	}
 	installationID := event.GetInstallation().GetID()
 
-	installClient, err := h.NewInstallationClient(installationID)
+	owner := event.PullRequest.Base.Repo.GetOwner().GetLogin()
+	repoName := event.PullRequest.Base.Repo.GetName()
+	ghClient, err := h.newClient(ctx, installationID, owner, repoName)
 	if err != nil {
 		return err
 	}
-
-	owner := event.PullRequest.Base.Repo.GetOwner().GetLogin()
-	repoName := event.PullRequest.Base.Repo.GetName()
-	ghClient := github.NewClientFromGHClient(installClient, owner, repoName, zerolog.Ctx(ctx))
 	ghSha := event.PullRequest.Base.GetSHA()
 
-	actionCfgPath, cfgFile, err := github.GetActionsCfg(ghClient, owner, repoName, ghSha)
+	c, err := repoConfig(ghClient, owner, repoName, ghSha)
 	if err != nil {
 		return err
 	}
-	if actionCfgPath == "" {
-		return fmt.Errorf("unable to find config files in sha %s", ghSha)
+	ghClient.SetShadowMode(h.shadowMode(c))
+
+	return ghClient.HandlePullRequestReviewEvent(c, &event)
+}
+
+func (h *PRCommentHandler) HandlePullRequestReviewThreadEvent(ctx context.Context, payload []byte) error {
+	var event gh.PullRequestReviewThreadEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return errors.Wrap(err, "failed to parse pull request review thread event payload")
 	}
+	installationID := event.GetInstallation().GetID()
 
-	var c github.PRBlockerConfig
-	err = yaml.Unmarshal(cfgFile, &c)
+	owner := event.PullRequest.Base.Repo.GetOwner().GetLogin()
+	repoName := event.PullRequest.Base.Repo.GetName()
+	ghClient, err := h.newClient(ctx, installationID, owner, repoName)
 	if err != nil {
-		return fmt.Errorf("unable to unmarshal config %q file: %s\n", actionCfgPath, err)
+		return err
 	}
+	ghSha := event.PullRequest.Base.GetSHA()
 
-	return ghClient.HandlePullRequestReviewEvent(c, &event)
+	c, err := repoConfig(ghClient, owner, repoName, ghSha)
+	if err != nil {
+		return err
+	}
+	ghClient.SetShadowMode(h.shadowMode(c))
+
+	return ghClient.HandlePullRequestReviewThreadEvent(c, &event)
 }
 
 func (h *PRCommentHandler) HandleIssueCommentEvent(ctx context.Context, payload []byte) error {
@@ -197,15 +341,13 @@ func (h *PRCommentHandler) HandleIssueCommentEvent(ctx context.Context, payload
 
 	installationID := event.GetInstallation().GetID()
 
-	installClient, err := h.NewInstallationClient(installationID)
-	if err != nil {
-		return err
-	}
-
 	owner := event.Repo.GetOwner().GetLogin()
 	repoName := event.Repo.GetName()
 
-	ghClient := github.NewClientFromGHClient(installClient, owner, repoName, zerolog.Ctx(ctx))
+	ghClient, err := h.newClient(ctx, installationID, owner, repoName)
+	if err != nil {
+		return err
+	}
 
 	prNumber := event.GetIssue().GetNumber()
 
@@ -216,19 +358,11 @@ func (h *PRCommentHandler) HandleIssueCommentEvent(ctx context.Context, payload
 
 	ghSha := pr.GetBase().GetSHA()
 
-	actionCfgPath, cfgFile, err := github.GetActionsCfg(ghClient, owner, repoName, ghSha)
+	c, err := repoConfig(ghClient, owner, repoName, ghSha)
 	if err != nil {
 		return err
 	}
-	if actionCfgPath == "" {
-		return fmt.Errorf("unable to find config files in sha %s", ghSha)
-	}
-
-	var c github.PRBlockerConfig
-	err = yaml.Unmarshal(cfgFile, &c)
-	if err != nil {
-		return fmt.Errorf("unable to unmarshal config %q file: %s\n", actionCfgPath, err)
-	}
+	ghClient.SetShadowMode(h.shadowMode(c))
 
 	return ghClient.HandleIssueCommentEvent(ctx, c.FlakeTracker, jobName, pr, &event)
 }
@@ -239,35 +373,76 @@ func (h *PRCommentHandler) HandleCheckRunEvent(ctx context.Context, payload []by
 		return errors.Wrap(err, "failed to parse check run event payload")
 	}
 
-	if event.GetAction() != "completed" {
+	switch event.GetAction() {
+	case "completed", "rerequested", "requested_action":
+	default:
 		return nil
 	}
 
 	installationID := event.GetInstallation().GetID()
 
-	installClient, err := h.NewInstallationClient(installationID)
+	owner := event.Repo.GetOwner().GetLogin()
+	repoName := event.Repo.GetName()
+	ghClient, err := h.newClient(ctx, installationID, owner, repoName)
+	if err != nil {
+		return err
+	}
+	// The check run might be of the head of a PR, which must not change the
+	// config it is evaluated with.
+	ghSha := event.Repo.GetDefaultBranch()
+
+	c, err := repoConfig(ghClient, owner, repoName, ghSha)
 	if err != nil {
 		return err
 	}
+	ghClient.SetShadowMode(h.shadowMode(c))
+
+	return ghClient.HandleCheckRunEvent(c, &event)
+}
+
+func (h *PRCommentHandler) HandleMergeGroupEvent(ctx context.Context, payload []byte) error {
+	var event gh.MergeGroupEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return errors.Wrap(err, "failed to parse merge group event payload")
+	}
+	installationID := event.GetInstallation().GetID()
 
 	owner := event.Repo.GetOwner().GetLogin()
 	repoName := event.Repo.GetName()
-	ghClient := github.NewClientFromGHClient(installClient, owner, repoName, zerolog.Ctx(ctx))
-	ghSha := event.GetCheckRun().GetHeadSHA()
+	ghClient, err := h.newClient(ctx, installationID, owner, repoName)
+	if err != nil {
+		return err
+	}
+	ghSha := event.GetMergeGroup().GetBaseSHA()
 
-	actionCfgPath, cfgFile, err := github.GetActionsCfg(ghClient, owner, repoName, ghSha)
+	c, err := repoConfig(ghClient, owner, repoName, ghSha)
 	if err != nil {
 		return err
 	}
-	if actionCfgPath == "" {
-		return fmt.Errorf("unable to find config files in sha %s", ghSha)
+	ghClient.SetShadowMode(h.shadowMode(c))
+
+	return ghClient.HandleMergeGroupEvent(c, &event)
+}
+
+func (h *PRCommentHandler) HandleCheckSuiteEvent(ctx context.Context, payload []byte) error {
+	var event gh.CheckSuiteEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return errors.Wrap(err, "failed to parse check suite event payload")
+	}
+
+	if event.GetAction() != "rerequested" {
+		return nil
 	}
 
-	var c github.PRBlockerConfig
-	err = yaml.Unmarshal(cfgFile, &c)
+	installationID := event.GetInstallation().GetID()
+
+	owner := event.Repo.GetOwner().GetLogin()
+	repoName := event.Repo.GetName()
+	ghClient, err := h.newClient(ctx, installationID, owner, repoName)
 	if err != nil {
-		return fmt.Errorf("unable to unmarshal config %q file: %s\n", actionCfgPath, err)
+		return err
 	}
 
-	return ghClient.HandleCheckRunEvent(c, &event)
+	// The PRs are re-evaluated with the config at their base SHA.
+	return ghClient.HandleCheckSuiteEvent(&event)
 }
@@ -0,0 +1,217 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cilium/github-actions/pkg/github"
+	"github.com/cilium/github-actions/pkg/githubtest"
+	gh "github.com/google/go-github/v84/github"
+	"github.com/palantir/go-githubapp/githubapp"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+require-msgs-in-commit:
+  - msg: "Signed-off-by"
+    helper: "https://docs.cilium.io/en/stable/contributing/development/contributing_guide/#developer-s-certificate-of-origin"
+    set-labels:
+    - "dont-merge/needs-sign-off"
+auto-label:
+  - "pending-review"
+block-pr-with:
+  labels-unset:
+  - regex-label: "release-note/.*"
+    helper: "Release note label not set, please set the appropriate release note."
+    set-labels:
+    - "dont-merge/needs-release-note"
+auto-merge:
+  enabled: true
+  label: "ready-to-merge"
+`
+
+// handleTestdata handles the webhook payload recorded in the given file of
+// testdata.
+func handleTestdata(t *testing.T, h *PRCommentHandler, eventType, deliveryID, file string) error {
+	payload, err := os.ReadFile("testdata/" + file)
+	if !assert.NoError(t, err) {
+		return err
+	}
+	return h.Handle(context.Background(), eventType, deliveryID, payload)
+}
+
+// handleEvent handles the given webhook event.
+func handleEvent(t *testing.T, h *PRCommentHandler, eventType, deliveryID string, event interface{}) error {
+	payload, err := json.Marshal(event)
+	if !assert.NoError(t, err) {
+		return err
+	}
+	return h.Handle(context.Background(), eventType, deliveryID, payload)
+}
+
+func TestPRCommentHandler_Handle(t *testing.T) {
+	srv := githubtest.NewServer()
+	defer srv.Close()
+
+	t.Setenv("CONFIG_PATHS", ".github/maintainers-little-helper.yaml")
+	srv.SetFile("cilium", "cilium", ".github/maintainers-little-helper.yaml", testConfig)
+	srv.SetBranchProtection("cilium", "cilium", "main", "ci/build")
+	srv.AddPR("cilium", "cilium", githubtest.PR{
+		Number:  1,
+		Author:  "alice",
+		BaseSHA: "9049f1265b7d61be4a8904a9a27120d2064dab3b",
+		Labels:  []string{"release-note/bug"},
+		Commits: []githubtest.Commit{
+			{
+				SHA:     "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+				Message: "datapath: Fix MTU of tunnel devices\n\nSigned-off-by: Alice <alice@example.com>",
+				Author:  "alice",
+			},
+		},
+	})
+	const headSHA = "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+
+	h := &PRCommentHandler{
+		ClientCreator: githubapp.NewClientCreator(srv.URL, srv.URL+"/graphql", githubtest.AppID, githubtest.PrivateKey()),
+		appID:         githubtest.AppID,
+	}
+
+	// The PR is labeled and its Mergeability check created once opened.
+	assert.NoError(t, handleTestdata(t, h, "pull_request", "1", "pull_request_opened.json"))
+	assert.Equal(t, []string{"pending-review", "release-note/bug"}, srv.Labels("cilium", "cilium", 1))
+	assert.Empty(t, srv.Comments("cilium", "cilium", 1))
+	assert.Equal(t, "success", srv.CheckRun("cilium", "cilium", headSHA, "Mergeability").GetConclusion())
+
+	// The PR is ready to merge once approved and its required check passed.
+	srv.AddReview("cilium", "cilium", 1, "bob", "APPROVED", headSHA)
+	srv.SetStatus("cilium", "cilium", headSHA, "ci/build", "success")
+	assert.NoError(t, handleTestdata(t, h, "status", "2", "status_success.json"))
+	assert.Equal(t, []string{"pending-review", "ready-to-merge", "release-note/bug"}, srv.Labels("cilium", "cilium", 1))
+	assert.Equal(t, "success", srv.CheckRun("cilium", "cilium", headSHA, "Auto-merge status").GetConclusion())
+
+	assert.Empty(t, srv.Unhandled())
+}
+
+func TestPRCommentHandler_Handle_retargeted(t *testing.T) {
+	srv := githubtest.NewServer()
+	defer srv.Close()
+
+	t.Setenv("CONFIG_PATHS", ".github/maintainers-little-helper.yaml")
+	srv.SetFile("cilium", "cilium", ".github/maintainers-little-helper.yaml", testConfig)
+	srv.SetFileAt("cilium", "cilium", "v1.16", ".github/maintainers-little-helper.yaml", `
+auto-label:
+  - "backport/1.16"
+`)
+	// The PR was retargeted from main to v1.16.
+	srv.AddPR("cilium", "cilium", githubtest.PR{
+		Number:  1,
+		Author:  "alice",
+		Base:    "v1.16",
+		BaseSHA: "9049f1265b7d61be4a8904a9a27120d2064dab3b",
+		Commits: []githubtest.Commit{{SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e"}},
+	})
+
+	h := &PRCommentHandler{
+		ClientCreator: githubapp.NewClientCreator(srv.URL, srv.URL+"/graphql", githubtest.AppID, githubtest.PrivateKey()),
+		appID:         githubtest.AppID,
+	}
+
+	// The configuration of the new base branch applies.
+	err := handleEvent(t, h, "pull_request", "1", &gh.PullRequestEvent{
+		Action: new("edited"),
+		Changes: &gh.EditChange{
+			Base: &gh.EditBase{Ref: &gh.EditRef{From: new("main")}},
+		},
+		PullRequest:  srv.PullRequest("cilium", "cilium", 1),
+		Installation: &gh.Installation{ID: new(int64(1))},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"backport/1.16"}, srv.Labels("cilium", "cilium", 1))
+	assert.Empty(t, srv.Unhandled())
+}
+
+func TestPRCommentHandler_Handle_debounced(t *testing.T) {
+	srv := githubtest.NewServer()
+	defer srv.Close()
+
+	t.Setenv("CONFIG_PATHS", ".github/maintainers-little-helper.yaml")
+	srv.SetFile("cilium", "cilium", ".github/maintainers-little-helper.yaml", testConfig)
+	srv.SetBranchProtection("cilium", "cilium", "main", "ci/build", "ci/test")
+	srv.AddPR("cilium", "cilium", githubtest.PR{
+		Number:  1,
+		Author:  "alice",
+		BaseSHA: "9049f1265b7d61be4a8904a9a27120d2064dab3b",
+		Labels:  []string{"release-note/bug"},
+		Commits: []githubtest.Commit{
+			{
+				SHA:     "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+				Message: "datapath: Fix MTU of tunnel devices\n\nSigned-off-by: Alice <alice@example.com>",
+				Author:  "alice",
+			},
+		},
+	})
+	const headSHA = "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+	srv.AddReview("cilium", "cilium", 1, "bob", "APPROVED", headSHA)
+
+	auditLog, err := github.OpenAuditLog(filepath.Join(t.TempDir(), "audit.log"))
+	assert.NoError(t, err)
+	defer auditLog.Close()
+	dt := newDeliveryTracker(time.Hour)
+	h := &PRCommentHandler{
+		ClientCreator: githubapp.NewClientCreator(srv.URL, srv.URL+"/graphql", githubtest.AppID, githubtest.PrivateKey()),
+		appID:         githubtest.AppID,
+		deliveries:    dt,
+		auditLog:      auditLog,
+		ciDebouncer:   github.NewCIDebouncer(time.Hour),
+	}
+	h.queue = newWorkQueue(h, dt, 2, 10, metrics.NewRegistry())
+
+	assert.NoError(t, handleTestdata(t, h, "pull_request", "1", "pull_request_opened.json"))
+	drainQueue(t, h.queue)
+
+	// The evaluation triggered by a required check passing waits for the
+	// debounce window, while ci/test is still pending.
+	srv.SetStatus("cilium", "cilium", headSHA, "ci/build", "success")
+	assert.NoError(t, handleTestdata(t, h, "status", "2", "status_success.json"))
+	assert.NotContains(t, srv.Labels("cilium", "cilium", 1), "ready-to-merge")
+	assert.True(t, dt.isProcessed("2"))
+
+	// Once the window expires, the evaluation is queued as work of its own,
+	// keyed by the PR.
+	srv.SetStatus("cilium", "cilium", headSHA, "ci/test", "success")
+	h.ciDebouncer.Flush()
+	drainQueue(t, h.queue)
+	assert.Contains(t, srv.Labels("cilium", "cilium", 1), "ready-to-merge")
+
+	records, err := auditLog.Query(context.Background(), "cilium", "cilium", 1)
+	assert.NoError(t, err)
+	var debounced int
+	for _, r := range records {
+		assert.NotEqual(t, "2", r.DeliveryID, "the status delivery was handled before the window expired")
+		if strings.HasPrefix(r.DeliveryID, "debounce:cilium/cilium#1:") {
+			debounced++
+		}
+	}
+	assert.NotZero(t, debounced)
+	assert.Empty(t, srv.Unhandled())
+}
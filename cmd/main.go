@@ -15,11 +15,14 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/cilium/github-actions/pkg/github"
 	"github.com/gregjones/httpcache"
 	"github.com/palantir/go-baseapp/baseapp"
 	"github.com/palantir/go-githubapp/githubapp"
@@ -29,6 +32,22 @@ import (
 
 var logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
 
+// defaultTeamCacheTTL is the default amount of time the members of a team are
+// cached. It can be overridden with the TEAM_CACHE_TTL environment variable.
+const defaultTeamCacheTTL = 10 * time.Minute
+
+// defaultDebounceWindow is the default amount of time the auto-merge
+// evaluations triggered by status and check run events of the same commit are
+// collapsed for. It can be overridden with the DEBOUNCE_WINDOW environment
+// variable, where 0 disables debouncing.
+const defaultDebounceWindow = 30 * time.Second
+
+// defaultShutdownWaitTime is the default amount of time we wait for the
+// in-flight requests, and then for the queued events, to be handled once
+// interrupted. It can be overridden with the SHUTDOWN_WAIT_TIME environment
+// variable.
+const defaultShutdownWaitTime = 30 * time.Second
+
 func main() {
 	if clientMode {
 		runClient()
@@ -49,6 +68,14 @@ func runServer() {
 			Port:    int(port),
 		},
 	}
+	shutdownWaitTime := defaultShutdownWaitTime
+	if wait := os.Getenv("SHUTDOWN_WAIT_TIME"); wait != "" {
+		shutdownWaitTime, err = time.ParseDuration(wait)
+		if err != nil {
+			panic(err)
+		}
+	}
+	config.Server.ShutdownWaitTime = &shutdownWaitTime
 	config.Github.SetValuesFromEnv("")
 	config.Github.App.PrivateKey = strings.Join(strings.Split(config.Github.App.PrivateKey, "\\n"), "\n")
 
@@ -60,31 +87,158 @@ func runServer() {
 		panic(err)
 	}
 
-	cc, err := githubapp.NewDefaultCachingClientCreator(
-		config.Github,
-		githubapp.WithClientUserAgent("maintainers-little-helper/0.0.1"),
-		githubapp.WithClientCaching(false, func() httpcache.Cache { return httpcache.NewMemoryCache() }),
-		githubapp.WithClientMiddleware(
-			githubapp.ClientMetrics(server.Registry()),
-		),
-	)
+	// The clients of each installation have their own rate limit
+	// middleware so that the quota of each installation is tracked
+	// separately.
+	limiter := github.NewRateLimiter(server.Registry())
+	cc, err := newInstallationClientCreator(limiter, func(rateLimit githubapp.ClientMiddleware) (githubapp.ClientCreator, error) {
+		return githubapp.NewDefaultCachingClientCreator(
+			config.Github,
+			githubapp.WithClientUserAgent("maintainers-little-helper/0.0.1"),
+			githubapp.WithClientCaching(false, func() httpcache.Cache { return httpcache.NewMemoryCache() }),
+			githubapp.WithClientMiddleware(
+				githubapp.ClientMetrics(server.Registry()),
+				rateLimit,
+			),
+		)
+	})
 	if err != nil {
 		panic(err)
 	}
 
+	teamCacheTTL := defaultTeamCacheTTL
+	if ttl := os.Getenv("TEAM_CACHE_TTL"); ttl != "" {
+		teamCacheTTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	redeliverWindow := defaultRedeliverWindow
+	if window := os.Getenv("REDELIVER_WINDOW"); window != "" {
+		redeliverWindow, err = time.ParseDuration(window)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	debounceWindow := defaultDebounceWindow
+	if window := os.Getenv("DEBOUNCE_WINDOW"); window != "" {
+		debounceWindow, err = time.ParseDuration(window)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// The processed webhook deliveries are only remembered across restarts
+	// if DELIVERY_LOG is set to the path of the log file.
+	deliveries := newDeliveryTracker(redeliverWindow)
+	if path := os.Getenv("DELIVERY_LOG"); path != "" {
+		deliveries, err = openDeliveryTracker(path, redeliverWindow)
+		if err != nil {
+			panic(err)
+		}
+		defer deliveries.close()
+	}
+
 	prCommentHandler := &PRCommentHandler{
 		ClientCreator: cc,
+		appID:         config.Github.App.IntegrationID,
+		teamCacheTTL:  teamCacheTTL,
+		deliveries:    deliveries,
+		shadow:        shadowMode,
+		metrics:       github.NewMetrics(server.Registry()),
 	}
 
-	webhookHandler := githubapp.NewDefaultEventDispatcher(config.Github, prCommentHandler)
+	// Mutations are only recorded if AUDIT_LOG is set to the path of the
+	// log file. The log is served by the admin API.
+	if path := os.Getenv("AUDIT_LOG"); path != "" {
+		prCommentHandler.auditLog, err = github.OpenAuditLog(path)
+		if err != nil {
+			panic(err)
+		}
+		defer prCommentHandler.auditLog.Close()
+	}
+	if debounceWindow > 0 {
+		prCommentHandler.ciDebouncer = github.NewCIDebouncer(debounceWindow)
+	}
+
+	// Failed webhook deliveries are only redelivered if REDELIVER_INTERVAL is
+	// set.
+	if interval := os.Getenv("REDELIVER_INTERVAL"); interval != "" {
+		redeliverInterval, err := time.ParseDuration(interval)
+		if err != nil {
+			panic(err)
+		}
+		rd := &redeliverer{
+			cc:         cc,
+			deliveries: prCommentHandler.deliveries,
+			interval:   redeliverInterval,
+			window:     redeliverWindow,
+		}
+		go rd.run(context.Background())
+	}
+
+	queueWorkers, err := intFromEnv("QUEUE_WORKERS", defaultQueueWorkers)
+	if err != nil {
+		panic(err)
+	}
+	queueSize, err := intFromEnv("QUEUE_SIZE", defaultQueueSize)
+	if err != nil {
+		panic(err)
+	}
+	queue := newWorkQueue(prCommentHandler, deliveries, queueWorkers, queueSize, server.Registry())
+	prCommentHandler.queue = queue
+
+	// The reconciler is disabled unless RECONCILE_INTERVAL is set.
+	if interval := os.Getenv("RECONCILE_INTERVAL"); interval != "" {
+		reconcileInterval, err := time.ParseDuration(interval)
+		if err != nil {
+			panic(err)
+		}
+		installations, err := parseInstallationIDs(os.Getenv("RECONCILE_INSTALLATIONS"))
+		if err != nil {
+			panic(err)
+		}
+		rec := newReconciler(prCommentHandler, reconcileInterval, installations, server.Registry())
+		go rec.run(context.Background())
+	}
+
+	webhookHandler := githubapp.NewDefaultEventDispatcher(config.Github, queue)
 	server.Mux().Handle(pat.Post(githubapp.DefaultWebhookRoute), webhookHandler)
 	server.Mux().HandleFunc(pat.Get("/healthz"), func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	// Start is blocking
+	// The admin API is disabled unless ADMIN_TOKEN is set.
+	if token := os.Getenv("ADMIN_TOKEN"); token != "" {
+		admin := &adminAPI{handler: prCommentHandler, token: token}
+		admin.register(server.Mux())
+	}
+
+	// Start is blocking until we are interrupted, then the queued events, and
+	// the evaluations waiting for their debounce window, are handled for up
+	// to the shutdown wait time.
 	err = server.Start()
+	if prCommentHandler.ciDebouncer != nil {
+		prCommentHandler.ciDebouncer.Flush()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownWaitTime)
+	defer cancel()
+	if err := queue.drain(ctx); err != nil {
+		logger.Err(err).Msg("Unable to handle all queued events")
+	}
 	if err != nil {
 		panic(err)
 	}
 }
+
+// intFromEnv returns the integer set in the given environment variable, or
+// 'def' if it is not set.
+func intFromEnv(name string, def int) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.Atoi(v)
+}
@@ -0,0 +1,482 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cilium/github-actions/pkg/github"
+	gh "github.com/google/go-github/v84/github"
+	"github.com/palantir/go-githubapp/githubapp"
+	"github.com/rcrowley/go-metrics"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultQueueWorkers = 4
+	defaultQueueSize    = 1000
+
+	// queueMaxRetries is the number of times an event that failed with a
+	// transient error is retried.
+	queueMaxRetries = 5
+	// queueBaseBackoff is the wait before the first retry, which doubles
+	// with each retry up to queueMaxBackoff.
+	queueBaseBackoff = time.Second
+	queueMaxBackoff  = time.Minute
+	// queueMaxRateLimitWait is the longest wait before retrying an event that
+	// hit a rate limit, which the primary rate limit resets within.
+	queueMaxRateLimitWait = time.Hour
+)
+
+// errAlreadyQueued is returned when pushing work with the same delivery ID as
+// work already queued.
+var errAlreadyQueued = errors.New("already queued")
+
+// work is a webhook delivery, or an evaluation scheduled while handling one,
+// waiting to be handled.
+type work struct {
+	ctx        context.Context
+	eventType  string
+	deliveryID string
+	payload    []byte
+	queuedAt   time.Time
+
+	// run, if set, is the evaluation to run instead of handling a delivery.
+	run func() error
+	// source is the ID of the delivery that scheduled the evaluation, if
+	// any.
+	source string
+	// done, if set, is called with the outcome of the evaluation once
+	// handled.
+	done func(err error)
+}
+
+// source tracks the evaluations scheduled while handling a delivery, which is
+// only processed once all of them succeeded.
+type source struct {
+	// pending is the number of evaluations not handled yet.
+	pending int
+	// handled is true once the delivery itself was handled.
+	handled bool
+	// failed is true if we gave up on any of the evaluations.
+	failed bool
+}
+
+// workQueue acknowledges webhook deliveries right away and handles them
+// asynchronously with a bounded pool of workers. Deliveries with the same key,
+// usually the same PR, are handled in order, one at a time. The state of each
+// delivery is recorded in the delivery tracker so that the deliveries that
+// were acknowledged but never handled successfully can be redelivered.
+type workQueue struct {
+	handler    githubapp.EventHandler
+	deliveries *deliveryTracker
+	size       int
+
+	mu sync.Mutex
+	// pending are the deliveries waiting to be handled, by key.
+	pending map[string][]*work
+	// queued are the delivery IDs of all pending or in-flight deliveries.
+	queued map[string]struct{}
+	// sources are the deliveries with scheduled evaluations, by ID.
+	sources map[string]*source
+	depth   int
+	// ready receives the keys that have pending deliveries and that are not
+	// being handled by any worker.
+	ready chan string
+	// draining is true once we stopped accepting deliveries.
+	draining bool
+	// idle, if set, is closed once the queue is empty.
+	idle chan struct{}
+
+	// metrics records the outcome of each delivery once, however many times
+	// it was attempted.
+	metrics    *github.Metrics
+	depthGauge metrics.Gauge
+	latency    metrics.Timer
+	retries    metrics.Counter
+	failures   metrics.Counter
+	duplicates metrics.Counter
+	rejected   metrics.Counter
+}
+
+func newWorkQueue(handler githubapp.EventHandler, deliveries *deliveryTracker, workers, size int, registry metrics.Registry) *workQueue {
+	q := &workQueue{
+		handler:    handler,
+		deliveries: deliveries,
+		size:       size,
+		pending:    map[string][]*work{},
+		queued:     map[string]struct{}{},
+		sources:    map[string]*source{},
+		ready:      make(chan string, size),
+		metrics:    github.NewMetrics(registry),
+		depthGauge: metrics.GetOrRegisterGauge("queue.depth", registry),
+		latency:    metrics.GetOrRegisterTimer("queue.latency", registry),
+		retries:    metrics.GetOrRegisterCounter("queue.retries", registry),
+		failures:   metrics.GetOrRegisterCounter("queue.failures", registry),
+		duplicates: metrics.GetOrRegisterCounter("queue.duplicates", registry),
+		rejected:   metrics.GetOrRegisterCounter("queue.rejected", registry),
+	}
+	for i := 0; i < workers; i++ {
+		go q.worker()
+	}
+	return q
+}
+
+func (q *workQueue) Handles() []string {
+	return q.handler.Handles()
+}
+
+// Handle queues the delivery to be handled asynchronously. It only fails if
+// the queue is full or draining, in which case GitHub marks the delivery as
+// failed so that it can be redelivered later.
+func (q *workQueue) Handle(ctx context.Context, eventType, deliveryID string, payload []byte) error {
+	if q.deliveries.isProcessed(deliveryID) {
+		zerolog.Ctx(ctx).Info().Str("delivery-id", deliveryID).Msg("Ignoring delivery already processed")
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.draining {
+		q.rejected.Inc(1)
+		return errors.New("unable to queue event: shutting down")
+	}
+	err := q.push(workKey(eventType, payload), &work{
+		// The request context is cancelled once we return.
+		ctx:        context.WithoutCancel(ctx),
+		eventType:  eventType,
+		deliveryID: deliveryID,
+		payload:    payload,
+		queuedAt:   time.Now(),
+	})
+	switch {
+	case errors.Is(err, errAlreadyQueued):
+		return nil
+	case err != nil:
+		return err
+	}
+	q.deliveries.markQueued(deliveryID)
+	return nil
+}
+
+// schedule queues the given evaluation to be run in order with the other work
+// of the given key. The evaluation is part of the handling of the delivery
+// 'sourceID', if any. Unlike deliveries, evaluations are still accepted while
+// draining so that the deliveries handled while draining are not cut short.
+// It returns errAlreadyQueued if work with the same ID is already queued, in
+// which case 'done' is never called.
+func (q *workQueue) schedule(ctx context.Context, key, id, sourceID string, run func() error, done func(err error)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	err := q.push(key, &work{
+		ctx:        context.WithoutCancel(ctx),
+		deliveryID: id,
+		queuedAt:   time.Now(),
+		run:        run,
+		source:     sourceID,
+		done:       done,
+	})
+	if err != nil || sourceID == "" {
+		return err
+	}
+	s, ok := q.sources[sourceID]
+	if !ok {
+		s = &source{}
+		q.sources[sourceID] = s
+	}
+	s.pending++
+	return nil
+}
+
+// push adds the work to the pending work of the given key, unless work with
+// the same delivery ID is already queued. q.mu must be held.
+func (q *workQueue) push(key string, w *work) error {
+	if _, ok := q.queued[w.deliveryID]; ok {
+		q.duplicates.Inc(1)
+		zerolog.Ctx(w.ctx).Info().Str("delivery-id", w.deliveryID).Msg("Ignoring delivery already queued")
+		return errAlreadyQueued
+	}
+	if q.depth >= q.size {
+		q.rejected.Inc(1)
+		return fmt.Errorf("unable to queue event: queue is full with %d events", q.depth)
+	}
+
+	q.queued[w.deliveryID] = struct{}{}
+	q.depth++
+	q.depthGauge.Update(int64(q.depth))
+	q.pending[key] = append(q.pending[key], w)
+	if len(q.pending[key]) == 1 {
+		// No worker is handling this key.
+		q.ready <- key
+	}
+	return nil
+}
+
+// worker handles all pending deliveries of each ready key, in order.
+func (q *workQueue) worker() {
+	for key := range q.ready {
+		for {
+			q.mu.Lock()
+			w := q.pending[key][0]
+			q.mu.Unlock()
+
+			err := q.handle(w)
+
+			q.mu.Lock()
+			q.finish(w, err)
+			delete(q.queued, w.deliveryID)
+			q.depth--
+			q.depthGauge.Update(int64(q.depth))
+			if q.depth == 0 && q.idle != nil {
+				close(q.idle)
+				q.idle = nil
+			}
+			q.pending[key] = q.pending[key][1:]
+			done := len(q.pending[key]) == 0
+			if done {
+				delete(q.pending, key)
+			}
+			q.mu.Unlock()
+			if w.done != nil {
+				w.done(err)
+			}
+			if done {
+				break
+			}
+		}
+	}
+}
+
+// handle handles a delivery, retrying it with exponential backoff while it
+// fails with transient errors. It returns the error we gave up on, if any.
+func (q *workQueue) handle(w *work) (err error) {
+	defer q.latency.UpdateSince(w.queuedAt)
+	if w.run == nil {
+		start := time.Now()
+		defer func() {
+			q.metrics.EventHandled(w.eventType, eventAction(w.payload), time.Since(start), err)
+		}()
+	}
+
+	backoff := queueBaseBackoff
+	for attempt := 0; ; attempt++ {
+		if w.run != nil {
+			err = w.run()
+		} else {
+			err = q.handler.Handle(w.ctx, w.eventType, w.deliveryID, w.payload)
+		}
+		if err == nil {
+			return nil
+		}
+		if attempt == queueMaxRetries || !isTransientError(err) {
+			q.failures.Inc(1)
+			zerolog.Ctx(w.ctx).Err(err).Fields(map[string]interface{}{
+				"delivery-id": w.deliveryID,
+				"attempts":    attempt + 1,
+			}).Msg("Giving up on event")
+			return err
+		}
+		q.retries.Inc(1)
+		time.Sleep(retryWait(err, backoff))
+		backoff = min(2*backoff, queueMaxBackoff)
+	}
+}
+
+// finish records the outcome of the given work in the delivery tracker. A
+// delivery is failed if either it or any of the evaluations it scheduled
+// failed, and it is only processed once all of them succeeded. q.mu must be
+// held.
+func (q *workQueue) finish(w *work, err error) {
+	id := w.deliveryID
+	if w.run != nil {
+		id = w.source
+	}
+	if id == "" {
+		return
+	}
+	s, ok := q.sources[id]
+	if !ok {
+		// The handler marks the deliveries it processed.
+		if err != nil {
+			q.deliveries.markFailed(id)
+		}
+		return
+	}
+
+	if w.run != nil {
+		s.pending--
+	} else {
+		s.handled = true
+	}
+	s.failed = s.failed || err != nil
+	switch {
+	case !s.handled:
+	case s.failed:
+		q.deliveries.markFailed(id)
+		delete(q.sources, id)
+	case s.pending > 0:
+		if w.run == nil {
+			// The handler marked the delivery as processed but its
+			// evaluations are still pending.
+			q.deliveries.markQueued(id)
+		}
+	default:
+		q.deliveries.markProcessed(id)
+		delete(q.sources, id)
+	}
+}
+
+// drain stops accepting deliveries and waits until all queued deliveries, and
+// the evaluations they scheduled, are handled or the context is done. The
+// deliveries left are recorded as queued, and are thus failed once we are
+// restarted.
+func (q *workQueue) drain(ctx context.Context) error {
+	q.mu.Lock()
+	q.draining = true
+	if q.depth == 0 {
+		q.mu.Unlock()
+		return nil
+	}
+	if q.idle == nil {
+		q.idle = make(chan struct{})
+	}
+	idle := q.idle
+	q.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		depth := q.depth
+		q.mu.Unlock()
+		logger.Warn().Int("depth", depth).Msg("Unable to drain the event queue")
+		return ctx.Err()
+	}
+}
+
+// isTransientError returns true if the error might go away by retrying,
+// e.g., rate limits, timeouts and server errors.
+func isTransientError(err error) bool {
+	var (
+		rateLimitErr      *gh.RateLimitError
+		abuseRateLimitErr *gh.AbuseRateLimitError
+		errResp           *gh.ErrorResponse
+		netErr            net.Error
+	)
+	switch {
+	case errors.As(err, &rateLimitErr), errors.As(err, &abuseRateLimitErr):
+		return true
+	case errors.As(err, &errResp):
+		return errResp.Response != nil && errResp.Response.StatusCode >= http.StatusInternalServerError
+	case errors.Is(err, context.DeadlineExceeded):
+		return true
+	case errors.As(err, &netErr):
+		return true
+	}
+	return false
+}
+
+// retryWait returns how long to wait before retrying work that failed with
+// the given transient error. Rate limited work is retried once the rate limit
+// resets, as retrying it any sooner would fail again, and other work after
+// the given backoff.
+func retryWait(err error, backoff time.Duration) time.Duration {
+	var (
+		rateLimitErr      *gh.RateLimitError
+		abuseRateLimitErr *gh.AbuseRateLimitError
+		wait              time.Duration
+	)
+	switch {
+	case errors.As(err, &rateLimitErr):
+		wait = time.Until(rateLimitErr.Rate.Reset.Time)
+	case errors.As(err, &abuseRateLimitErr):
+		wait = abuseRateLimitErr.GetRetryAfter()
+	}
+	return min(max(wait, backoff), queueMaxRateLimitWait)
+}
+
+// workKey returns the key used to serialize the handling of the given event.
+// Events of the same PR share the same key. Events that are not tied to a
+// single PR are keyed by their commit SHA, and their handlers schedule the
+// evaluations of each of the PRs of the commit under the key of the PR.
+func workKey(eventType string, payload []byte) string {
+	var p struct {
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		PullRequest struct {
+			Number int `json:"number"`
+		} `json:"pull_request"`
+		Issue struct {
+			Number int `json:"number"`
+		} `json:"issue"`
+		SHA      string `json:"sha"`
+		CheckRun struct {
+			HeadSHA      string `json:"head_sha"`
+			PullRequests []struct {
+				Number int `json:"number"`
+			} `json:"pull_requests"`
+		} `json:"check_run"`
+		CheckSuite struct {
+			HeadSHA      string `json:"head_sha"`
+			PullRequests []struct {
+				Number int `json:"number"`
+			} `json:"pull_requests"`
+		} `json:"check_suite"`
+		MergeGroup struct {
+			HeadSHA string `json:"head_sha"`
+		} `json:"merge_group"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		// Handled on its own, the handler will report the invalid payload.
+		return eventType
+	}
+
+	repo := p.Repository.FullName
+	switch {
+	case p.PullRequest.Number != 0:
+		return prWorkKey(repo, p.PullRequest.Number)
+	case p.Issue.Number != 0:
+		return prWorkKey(repo, p.Issue.Number)
+	case len(p.CheckRun.PullRequests) == 1:
+		return prWorkKey(repo, p.CheckRun.PullRequests[0].Number)
+	case len(p.CheckSuite.PullRequests) == 1:
+		return prWorkKey(repo, p.CheckSuite.PullRequests[0].Number)
+	case p.CheckRun.HeadSHA != "":
+		return repo + "@" + p.CheckRun.HeadSHA
+	case p.CheckSuite.HeadSHA != "":
+		return repo + "@" + p.CheckSuite.HeadSHA
+	case p.MergeGroup.HeadSHA != "":
+		return repo + "@" + p.MergeGroup.HeadSHA
+	default:
+		return repo + "@" + p.SHA
+	}
+}
+
+// prWorkKey returns the key used to serialize the handling of the events of
+// the given PR.
+func prWorkKey(repo string, number int) string {
+	return fmt.Sprintf("%s#%d", repo, number)
+}
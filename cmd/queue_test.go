@@ -0,0 +1,351 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEventHandler records the deliveries it handles, in order, by key.
+type fakeEventHandler struct {
+	// handle, if set, is called to handle each delivery.
+	handle func(ctx context.Context, deliveryID string) error
+
+	mu      sync.Mutex
+	handled map[string][]string
+}
+
+func (h *fakeEventHandler) Handles() []string {
+	return []string{"pull_request"}
+}
+
+func (h *fakeEventHandler) Handle(ctx context.Context, eventType, deliveryID string, payload []byte) error {
+	h.mu.Lock()
+	if h.handled == nil {
+		h.handled = map[string][]string{}
+	}
+	key := workKey(eventType, payload)
+	h.handled[key] = append(h.handled[key], deliveryID)
+	h.mu.Unlock()
+	if h.handle == nil {
+		return nil
+	}
+	return h.handle(ctx, deliveryID)
+}
+
+// prPayload returns the payload of an event of the given PR.
+func prPayload(number int) []byte {
+	return fmt.Appendf(nil, `{"repository":{"full_name":"cilium/cilium"},"pull_request":{"number":%d}}`, number)
+}
+
+// drainQueue waits until all the work queued is handled.
+func drainQueue(t *testing.T, q *workQueue) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	assert.NoError(t, q.drain(ctx))
+}
+
+func TestWorkQueue_order(t *testing.T) {
+	h := &fakeEventHandler{}
+	q := newWorkQueue(h, newDeliveryTracker(time.Hour), 4, 100, metrics.NewRegistry())
+
+	want := map[string][]string{}
+	for i := range 30 {
+		number := i%3 + 1
+		id := fmt.Sprintf("d%d", i)
+		assert.NoError(t, q.Handle(context.Background(), "pull_request", id, prPayload(number)))
+		key := prWorkKey("cilium/cilium", number)
+		want[key] = append(want[key], id)
+	}
+	drainQueue(t, q)
+
+	assert.Equal(t, want, h.handled)
+}
+
+func TestWorkQueue_dedup(t *testing.T) {
+	release := make(chan struct{})
+	h := &fakeEventHandler{
+		handle: func(context.Context, string) error {
+			<-release
+			return nil
+		},
+	}
+	dt := newDeliveryTracker(time.Hour)
+	q := newWorkQueue(h, dt, 1, 10, metrics.NewRegistry())
+
+	// The delivery is in flight.
+	assert.NoError(t, q.Handle(context.Background(), "pull_request", "a", prPayload(1)))
+	assert.NoError(t, q.Handle(context.Background(), "pull_request", "a", prPayload(1)))
+	// The delivery is pending.
+	assert.NoError(t, q.Handle(context.Background(), "pull_request", "b", prPayload(1)))
+	assert.NoError(t, q.Handle(context.Background(), "pull_request", "b", prPayload(1)))
+	close(release)
+	drainQueue(t, q)
+
+	assert.Equal(t, map[string][]string{"cilium/cilium#1": {"a", "b"}}, h.handled)
+	assert.Equal(t, int64(2), q.duplicates.Count())
+
+	// Deliveries already processed are ignored.
+	q = newWorkQueue(h, dt, 1, 10, metrics.NewRegistry())
+	dt.markProcessed("c")
+	assert.NoError(t, q.Handle(context.Background(), "pull_request", "c", prPayload(1)))
+	drainQueue(t, q)
+	assert.Equal(t, map[string][]string{"cilium/cilium#1": {"a", "b"}}, h.handled)
+}
+
+func TestWorkQueue_full(t *testing.T) {
+	release := make(chan struct{})
+	h := &fakeEventHandler{
+		handle: func(context.Context, string) error {
+			<-release
+			return nil
+		},
+	}
+	q := newWorkQueue(h, newDeliveryTracker(time.Hour), 1, 2, metrics.NewRegistry())
+
+	assert.NoError(t, q.Handle(context.Background(), "pull_request", "a", prPayload(1)))
+	assert.NoError(t, q.Handle(context.Background(), "pull_request", "b", prPayload(2)))
+	assert.ErrorContains(t, q.Handle(context.Background(), "pull_request", "c", prPayload(3)), "queue is full")
+	assert.ErrorContains(t, q.schedule(context.Background(), "cilium/cilium#3", "c:evaluate", "a", func() error { return nil }, nil), "queue is full")
+	assert.Equal(t, int64(2), q.rejected.Count())
+	close(release)
+	drainQueue(t, q)
+
+	// The queue accepts deliveries again once it has room.
+	q = newWorkQueue(h, newDeliveryTracker(time.Hour), 1, 2, metrics.NewRegistry())
+	assert.NoError(t, q.Handle(context.Background(), "pull_request", "c", prPayload(3)))
+	drainQueue(t, q)
+	assert.Equal(t, []string{"c"}, h.handled["cilium/cilium#3"])
+}
+
+func TestWorkQueue_drain(t *testing.T) {
+	release := make(chan struct{})
+	h := &fakeEventHandler{
+		handle: func(context.Context, string) error {
+			<-release
+			return nil
+		},
+	}
+	dt := newDeliveryTracker(time.Hour)
+	q := newWorkQueue(h, dt, 1, 10, metrics.NewRegistry())
+	assert.NoError(t, q.Handle(context.Background(), "pull_request", "a", prPayload(1)))
+	assert.NoError(t, q.Handle(context.Background(), "pull_request", "b", prPayload(1)))
+
+	// Draining times out while deliveries are pending, and stops accepting
+	// deliveries.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, q.drain(ctx), context.DeadlineExceeded)
+	assert.ErrorContains(t, q.Handle(context.Background(), "pull_request", "c", prPayload(1)), "shutting down")
+
+	close(release)
+	drainQueue(t, q)
+	assert.Equal(t, map[string][]string{"cilium/cilium#1": {"a", "b"}}, h.handled)
+}
+
+func TestWorkQueue_failed(t *testing.T) {
+	var q *workQueue
+	h := &fakeEventHandler{
+		handle: func(ctx context.Context, deliveryID string) error {
+			switch deliveryID {
+			case "failed":
+				return errors.New("invalid payload")
+			case "failed-evaluation":
+				return q.schedule(ctx, "cilium/cilium#2", deliveryID+":evaluate", deliveryID, func() error {
+					return errors.New("unable to merge")
+				}, nil)
+			case "evaluated":
+				return q.schedule(ctx, "cilium/cilium#3", deliveryID+":evaluate", deliveryID, func() error {
+					return nil
+				}, nil)
+			}
+			return nil
+		},
+	}
+	dt := newDeliveryTracker(time.Hour)
+	q = newWorkQueue(h, dt, 2, 10, metrics.NewRegistry())
+
+	for _, id := range []string{"failed", "failed-evaluation", "evaluated"} {
+		assert.NoError(t, q.Handle(context.Background(), "pull_request", id, prPayload(1)))
+	}
+	drainQueue(t, q)
+
+	assert.True(t, dt.isFailed("failed"))
+	assert.True(t, dt.isFailed("failed-evaluation"))
+	assert.False(t, dt.isFailed("evaluated"))
+	assert.True(t, dt.isProcessed("evaluated"))
+	assert.Equal(t, int64(2), q.failures.Count())
+}
+
+func TestWorkQueue_eventsHandled(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+	)
+	h := &fakeEventHandler{
+		handle: func(_ context.Context, deliveryID string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts[deliveryID]++
+			switch {
+			case deliveryID == "retried" && attempts[deliveryID] == 1:
+				return &net.OpError{Op: "dial", Err: timeoutError{}}
+			case deliveryID == "failed":
+				return errors.New("invalid payload")
+			}
+			return nil
+		},
+	}
+	registry := metrics.NewRegistry()
+	q := newWorkQueue(h, newDeliveryTracker(time.Hour), 2, 10, registry)
+
+	for i, id := range []string{"handled", "retried", "failed"} {
+		assert.NoError(t, q.Handle(context.Background(), "pull_request", id, prPayload(i+1)))
+	}
+	drainQueue(t, q)
+
+	// Deliveries are recorded once, however many times they were attempted.
+	assert.Equal(t, 2, attempts["retried"])
+	assert.Equal(t, int64(1), q.retries.Count())
+	count := func(name string) int64 {
+		counter, _ := registry.Get(name).(metrics.Counter)
+		if counter == nil {
+			return 0
+		}
+		return counter.Count()
+	}
+	assert.Equal(t, int64(2), count("events.handled[event:pull_request,action:none,result:success]"))
+	assert.Equal(t, int64(1), count("events.handled[event:pull_request,action:none,result:error]"))
+	assert.Equal(t, int64(3), registry.Get("events.latency[event:pull_request]").(metrics.Timer).Count())
+}
+
+// timeoutError is a net.Error that timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func Test_isTransientError(t *testing.T) {
+	errorResponse := func(statusCode int) error {
+		return &gh.ErrorResponse{Response: &http.Response{StatusCode: statusCode}}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "rate limit",
+			err:  &gh.RateLimitError{},
+			want: true,
+		},
+		{
+			name: "secondary rate limit",
+			err:  fmt.Errorf("unable to merge: %w", &gh.AbuseRateLimitError{}),
+			want: true,
+		},
+		{
+			name: "server error",
+			err:  errorResponse(http.StatusBadGateway),
+			want: true,
+		},
+		{
+			name: "client error",
+			err:  errorResponse(http.StatusUnprocessableEntity),
+			want: false,
+		},
+		{
+			name: "error response without response",
+			err:  &gh.ErrorResponse{},
+			want: false,
+		},
+		{
+			name: "deadline exceeded",
+			err:  fmt.Errorf("unable to list PRs: %w", context.DeadlineExceeded),
+			want: true,
+		},
+		{
+			name: "network error",
+			err:  &net.OpError{Op: "dial", Err: timeoutError{}},
+			want: true,
+		},
+		{
+			name: "other error",
+			err:  errors.New("invalid configuration"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isTransientError(tt.err))
+		})
+	}
+}
+
+func Test_retryWait(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{
+			name: "rate limit",
+			err:  fmt.Errorf("unable to get PR: %w", &gh.RateLimitError{Rate: gh.Rate{Reset: gh.Timestamp{Time: time.Now().Add(10 * time.Minute)}}}),
+			want: 10 * time.Minute,
+		},
+		{
+			name: "rate limit already reset",
+			err:  &gh.RateLimitError{Rate: gh.Rate{Reset: gh.Timestamp{Time: time.Now().Add(-time.Minute)}}},
+			want: 2 * time.Second,
+		},
+		{
+			name: "rate limit reset too late",
+			err:  &gh.RateLimitError{Rate: gh.Rate{Reset: gh.Timestamp{Time: time.Now().Add(2 * time.Hour)}}},
+			want: queueMaxRateLimitWait,
+		},
+		{
+			name: "secondary rate limit",
+			err:  &gh.AbuseRateLimitError{RetryAfter: new(time.Minute)},
+			want: time.Minute,
+		},
+		{
+			name: "secondary rate limit without Retry-After",
+			err:  &gh.AbuseRateLimitError{},
+			want: 2 * time.Second,
+		},
+		{
+			name: "server error",
+			err:  &gh.ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadGateway}},
+			want: 2 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, retryWait(tt.err, 2*time.Second), float64(time.Second))
+		})
+	}
+}
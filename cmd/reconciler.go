@@ -0,0 +1,295 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cilium/github-actions/pkg/github"
+	gh "github.com/google/go-github/v84/github"
+	"github.com/rcrowley/go-metrics"
+)
+
+// reconcilerMinRemaining is the number of requests of the core rate limit
+// that the reconciler leaves to the webhook handlers. Once reached, the
+// reconciler waits for the rate limit to reset.
+const reconcilerMinRemaining = 500
+
+// reconciler periodically evaluates all open PRs of the installations, as the
+// webhook handlers do, to repair the effects of missed webhooks.
+type reconciler struct {
+	handler  *PRCommentHandler
+	interval time.Duration
+	// installations are the IDs of the installations to reconcile. All
+	// installations are reconciled if empty.
+	installations map[int64]struct{}
+
+	passes   metrics.Counter
+	prs      metrics.Counter
+	errors   metrics.Counter
+	duration metrics.Timer
+}
+
+func newReconciler(handler *PRCommentHandler, interval time.Duration, installations map[int64]struct{}, registry metrics.Registry) *reconciler {
+	return &reconciler{
+		handler:       handler,
+		interval:      interval,
+		installations: installations,
+		passes:        metrics.GetOrRegisterCounter("reconciler.passes", registry),
+		prs:           metrics.GetOrRegisterCounter("reconciler.prs", registry),
+		errors:        metrics.GetOrRegisterCounter("reconciler.errors", registry),
+		duration:      metrics.GetOrRegisterTimer("reconciler.duration", registry),
+	}
+}
+
+// parseInstallationIDs parses a comma separated list of installation IDs.
+func parseInstallationIDs(s string) (map[int64]struct{}, error) {
+	ids := map[int64]struct{}{}
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid installation ID %q: %w", field, err)
+		}
+		ids[id] = struct{}{}
+	}
+	return ids, nil
+}
+
+// run reconciles all installations every interval until the context is
+// done.
+func (r *reconciler) run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reconcile(ctx)
+		}
+	}
+}
+
+// reconcile runs a single pass over all installations.
+func (r *reconciler) reconcile(ctx context.Context) {
+	start := time.Now()
+	var prs, errs int
+	defer func() {
+		r.passes.Inc(1)
+		r.prs.Inc(int64(prs))
+		r.errors.Inc(int64(errs))
+		r.duration.UpdateSince(start)
+		logger.Info().Fields(map[string]interface{}{
+			"prs":      prs,
+			"errors":   errs,
+			"duration": time.Since(start).String(),
+		}).Msg("Reconciler pass finished")
+	}()
+
+	appClient, err := r.handler.NewAppClient()
+	if err != nil {
+		logger.Err(err).Msg("Unable to create app client")
+		errs++
+		return
+	}
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		installations, resp, err := appClient.Apps.ListInstallations(ctx, opts)
+		if err != nil {
+			logger.Err(err).Msg("Unable to list installations")
+			errs++
+			return
+		}
+		for _, inst := range installations {
+			if _, ok := r.installations[inst.GetID()]; len(r.installations) != 0 && !ok {
+				continue
+			}
+			n, e := r.reconcileInstallation(ctx, inst.GetID())
+			prs += n
+			errs += e
+		}
+		if resp.NextPage == 0 {
+			return
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// reconcileInstallation reconciles all open PRs of the repositories of the
+// given installation. It returns the number of PRs reconciled and the number
+// of errors.
+func (r *reconciler) reconcileInstallation(ctx context.Context, installationID int64) (prs, errs int) {
+	installClient, err := r.handler.NewInstallationClient(installationID)
+	if err != nil {
+		logger.Err(err).Int64("installation-id", installationID).Msg("Unable to create installation client")
+		return 0, 1
+	}
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		repos, resp, err := installClient.Apps.ListRepos(ctx, opts)
+		if err != nil {
+			logger.Err(err).Int64("installation-id", installationID).Msg("Unable to list repositories")
+			return prs, errs + 1
+		}
+		for _, repo := range repos.Repositories {
+			if repo.GetArchived() {
+				continue
+			}
+			n, e := r.reconcileRepo(ctx, installationID, installClient, repo)
+			prs += n
+			errs += e
+		}
+		if resp.NextPage == 0 {
+			return prs, errs
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// reconcileRepo reconciles all open PRs of the given repository. The PRs are
+// evaluated by the work queue, one at a time with the webhook deliveries of
+// the same PR, and each page of PRs is reconciled before listing the next
+// one.
+func (r *reconciler) reconcileRepo(ctx context.Context, installationID int64, installClient *gh.Client, repo *gh.Repository) (prs, errs int) {
+	owner, repoName := repo.GetOwner().GetLogin(), repo.GetName()
+	log := logger.With().Str("owner", owner).Str("repo", repoName).Logger()
+	ctx = log.WithContext(ctx)
+
+	ghClient, err := r.handler.newClient(ctx, installationID, owner, repoName)
+	if err != nil {
+		log.Err(err).Msg("Unable to create client")
+		return 0, 1
+	}
+
+	// PRs targeting the same base SHA share the same config.
+	cfgs := map[string]*github.PRBlockerConfig{}
+	opts := &gh.PullRequestListOptions{
+		State:       "open",
+		ListOptions: gh.ListOptions{PerPage: 100},
+	}
+	for {
+		openPRs, resp, err := installClient.PullRequests.List(ctx, owner, repoName, opts)
+		if err != nil {
+			log.Err(err).Msg("Unable to list open PRs")
+			return prs, errs + 1
+		}
+		if err := waitForRateLimit(ctx, installClient); err != nil {
+			log.Err(err).Msg("Unable to wait for rate limit")
+			return prs, errs + 1
+		}
+
+		type result struct {
+			number int
+			err    error
+		}
+		results := make(chan result, len(openPRs))
+		scheduled := 0
+		for _, pr := range openPRs {
+			baseSHA := pr.GetBase().GetSHA()
+			cfg, ok := cfgs[baseSHA]
+			if !ok {
+				cfg, err = loadRepoConfig(ghClient, owner, repoName, baseSHA)
+				if err != nil {
+					log.Err(err).Int("pr-number", pr.GetNumber()).Msg("Unable to load config")
+					errs++
+				}
+				cfgs[baseSHA] = cfg
+			}
+			if cfg == nil {
+				continue
+			}
+
+			number := pr.GetNumber()
+			key := prWorkKey(owner+"/"+repoName, number)
+			err := r.handler.queue.schedule(ctx, key, "reconcile:"+key, "", func() error {
+				return r.reconcilePR(ctx, installationID, owner, repoName, *cfg, number)
+			}, func(err error) {
+				results <- result{number: number, err: err}
+			})
+			switch {
+			case errors.Is(err, errAlreadyQueued):
+				// Still being reconciled by a previous pass.
+			case err != nil:
+				log.Err(err).Int("pr-number", number).Msg("Unable to schedule PR")
+				errs++
+			default:
+				scheduled++
+			}
+		}
+		for range scheduled {
+			select {
+			case <-ctx.Done():
+				return prs, errs + 1
+			case res := <-results:
+				prs++
+				if res.err != nil {
+					log.Err(res.err).Int("pr-number", res.number).Msg("Unable to reconcile PR")
+					errs++
+				}
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return prs, errs
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// reconcilePR reconciles the given PR with a client of its own, as the work
+// queue might evaluate several PRs of the repository at once. The PR is
+// fetched again as it might have changed, or been closed, since it was listed.
+func (r *reconciler) reconcilePR(ctx context.Context, installationID int64, owner, repoName string, cfg github.PRBlockerConfig, number int) error {
+	ghClient, err := r.handler.newClient(ctx, installationID, owner, repoName)
+	if err != nil {
+		return err
+	}
+	ghClient.SetShadowMode(r.handler.shadowMode(cfg))
+	return ghClient.ReevaluatePR(cfg, number)
+}
+
+// waitForRateLimit blocks until the core rate limit of the given client has
+// more than reconcilerMinRemaining requests left. Getting the rate limit does
+// not count against it.
+func waitForRateLimit(ctx context.Context, client *gh.Client) error {
+	limits, _, err := client.RateLimit.Get(ctx)
+	if err != nil {
+		return err
+	}
+	core := limits.GetCore()
+	if core.Remaining > reconcilerMinRemaining {
+		return nil
+	}
+	wait := time.Until(core.Reset.Time)
+	logger.Info().Fields(map[string]interface{}{
+		"remaining": core.Remaining,
+		"wait":      wait.String(),
+	}).Msg("Reconciler waiting for rate limit to reset")
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
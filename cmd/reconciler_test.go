@@ -0,0 +1,157 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/palantir/go-githubapp/githubapp"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseInstallationIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    map[int64]struct{}
+		wantErr string
+	}{
+		{
+			name: "empty",
+			s:    "",
+			want: map[int64]struct{}{},
+		},
+		{
+			name: "list with spaces",
+			s:    "1, 42,,7",
+			want: map[int64]struct{}{1: {}, 42: {}, 7: {}},
+		},
+		{
+			name:    "invalid ID",
+			s:       "1,cilium",
+			wantErr: `invalid installation ID "cilium"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseInstallationIDs(tt.s)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReconciler_reconcile(t *testing.T) {
+	srv := githubtest.NewServer()
+	defer srv.Close()
+
+	t.Setenv("CONFIG_PATHS", ".github/maintainers-little-helper.yaml")
+	srv.SetFile("cilium", "cilium", ".github/maintainers-little-helper.yaml", testConfig)
+	srv.SetFile("cilium", "hubble", ".github/maintainers-little-helper.yaml", "mode: shadow\n"+testConfig)
+	for _, repoName := range []string{"cilium", "hubble", "tetragon"} {
+		for i := range 3 {
+			srv.AddPR("cilium", repoName, githubtest.PR{
+				Author: "alice",
+				Commits: []githubtest.Commit{{
+					SHA:     fmt.Sprintf("%s-%d", repoName, i),
+					Message: "Fix the bug\n\nSigned-off-by: Alice <alice@example.com>",
+					Author:  "alice",
+				}},
+			})
+		}
+	}
+
+	h := &PRCommentHandler{
+		ClientCreator: githubapp.NewClientCreator(srv.URL, srv.URL+"/graphql", githubtest.AppID, githubtest.PrivateKey()),
+		appID:         githubtest.AppID,
+	}
+	registry := metrics.NewRegistry()
+	h.queue = newWorkQueue(h, newDeliveryTracker(time.Hour), 4, 100, registry)
+	r := newReconciler(h, time.Hour, nil, registry)
+
+	r.reconcile(context.Background())
+
+	// The PRs of the repositories with a config were all reconciled through
+	// the queue.
+	assert.Equal(t, int64(6), r.prs.Count())
+	assert.Equal(t, int64(0), r.errors.Count())
+	assert.Equal(t, int64(6), h.queue.latency.Count())
+	for number := 1; number <= 3; number++ {
+		assert.Contains(t, srv.Labels("cilium", "cilium", number), "dont-merge/needs-release-note")
+		// The repository is in shadow mode.
+		assert.NotContains(t, srv.Labels("cilium", "hubble", number), "dont-merge/needs-release-note")
+		assert.NotContains(t, srv.Labels("cilium", "tetragon", number), "dont-merge/needs-release-note")
+	}
+
+	// The rate limit is checked once per page of PRs.
+	var rateLimitChecks int
+	for _, req := range srv.Requests() {
+		if req == "GET /rate_limit" {
+			rateLimitChecks++
+		}
+	}
+	assert.Equal(t, 3, rateLimitChecks)
+
+	// Only the given installations are reconciled.
+	r = newReconciler(h, time.Hour, map[int64]struct{}{2: {}}, metrics.NewRegistry())
+	r.reconcile(context.Background())
+	assert.Equal(t, int64(0), r.prs.Count())
+
+	assert.Empty(t, srv.Unhandled())
+}
+
+func TestReconciler_reconcilePR(t *testing.T) {
+	srv := githubtest.NewServer()
+	defer srv.Close()
+
+	t.Setenv("CONFIG_PATHS", ".github/maintainers-little-helper.yaml")
+	srv.SetFile("cilium", "cilium", ".github/maintainers-little-helper.yaml", testConfig)
+	srv.AddPR("cilium", "cilium", githubtest.PR{
+		Author: "alice",
+		Commits: []githubtest.Commit{{
+			SHA:     "abc",
+			Message: "Fix the bug\n\nSigned-off-by: Alice <alice@example.com>",
+			Author:  "alice",
+		}},
+	})
+	h := &PRCommentHandler{
+		ClientCreator: githubapp.NewClientCreator(srv.URL, srv.URL+"/graphql", githubtest.AppID, githubtest.PrivateKey()),
+		appID:         githubtest.AppID,
+	}
+	r := newReconciler(h, time.Hour, nil, metrics.NewRegistry())
+	ghClient, err := h.newClient(context.Background(), 1, "cilium", "cilium")
+	assert.NoError(t, err)
+	cfg, err := repoConfig(ghClient, "cilium", "cilium", "main")
+	assert.NoError(t, err)
+
+	// The release note label is set once the PR was listed, which the
+	// evaluation must see.
+	_, _, err = srv.Client().Issues.AddLabelsToIssue(context.Background(), "cilium", "cilium", 1, []string{"release-note/bug"})
+	assert.NoError(t, err)
+
+	err = r.reconcilePR(context.Background(), 1, "cilium", "cilium", cfg, 1)
+	assert.NoError(t, err)
+	assert.NotContains(t, srv.Labels("cilium", "cilium", 1), "dont-merge/needs-release-note")
+	assert.Empty(t, srv.Unhandled())
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	gh "github.com/google/go-github/v84/github"
+)
+
+// permissionRanks ranks the repository roles from the least to the most
+// privileged.
+var permissionRanks = map[string]int{
+	"none":     0,
+	"read":     1,
+	"triage":   2,
+	"write":    3,
+	"maintain": 4,
+	"admin":    5,
+}
+
+var (
+	// coAuthorNoReplyRegexp matches the "Co-authored-by" trailers that use
+	// the GitHub no-reply email address, from which the login can be
+	// extracted.
+	coAuthorNoReplyRegexp = regexp.MustCompile(`(?mi)^Co-authored-by:.*<(?:[0-9]+\+)?([^@>]+)@users\.noreply\.github\.com>`)
+)
+
+type ApproversConfig struct {
+	// Permission counts approvals from collaborators with at least this
+	// permission in the repository, one of "read", "triage", "write",
+	// "maintain" or "admin".
+	Permission string `yaml:"permission,omitempty"`
+	// Teams counts approvals from members of these teams, either in the
+	// form "org/team" or "team" for teams of the repository owner.
+	Teams []string `yaml:"teams,omitempty"`
+	// Users counts approvals from these users.
+	Users []string `yaml:"users,omitempty"`
+	// ExcludeCommitAuthors does not count approvals from users that authored,
+	// or co-authored, commits of the PR.
+	ExcludeCommitAuthors bool `yaml:"exclude-commit-authors,omitempty"`
+}
+
+// UnmarshalYAML validates the approvers configuration once parsed so that an
+// unknown permission is reported when the configuration is loaded rather than
+// when an approval is evaluated.
+func (ac *ApproversConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain ApproversConfig
+	if err := unmarshal((*plain)(ac)); err != nil {
+		return err
+	}
+	// Every collaborator has at least the "none" permission.
+	if _, ok := permissionRanks[ac.Permission]; ac.Permission == "none" || (ac.Permission != "" && !ok) {
+		return fmt.Errorf("unknown permission %q, must be one of \"read\", \"triage\", \"write\", \"maintain\" or \"admin\"", ac.Permission)
+	}
+	return nil
+}
+
+// isBot returns true if the given user is a bot account.
+func isBot(user *gh.User) bool {
+	return strings.EqualFold(user.GetType(), "Bot") || strings.HasSuffix(user.GetLogin(), "[bot]")
+}
+
+// getCommitAuthors returns the logins, in lower case, of the authors and
+// co-authors of all commits of the given PR. Co-authors are only detected if
+// they use their GitHub no-reply email address.
+func (c *Client) getCommitAuthors(ctx context.Context, owner, repoName string, prNumber int) (map[string]struct{}, error) {
+	authors := map[string]struct{}{}
+	nextPage := 0
+	for {
+		commits, resp, err := c.GHClient.PullRequests.ListCommits(ctx, owner, repoName, prNumber, &gh.ListOptions{
+			Page:    nextPage,
+			PerPage: 100,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			if login := commit.GetAuthor().GetLogin(); login != "" {
+				authors[strings.ToLower(login)] = struct{}{}
+			}
+			for _, m := range coAuthorNoReplyRegexp.FindAllStringSubmatch(commit.GetCommit().GetMessage(), -1) {
+				authors[strings.ToLower(m[1])] = struct{}{}
+			}
+		}
+		nextPage = resp.NextPage
+		if nextPage == 0 {
+			break
+		}
+	}
+	return authors, nil
+}
+
+// approvalEligibility decides which approvals are counted towards the minimal
+// number of approvals of a PR.
+type approvalEligibility struct {
+	c        *Client
+	cfg      *ApproversConfig
+	owner    string
+	repoName string
+	prNumber int
+
+	commitAuthors map[string]struct{}
+}
+
+// ineligibleReason returns why the approval from the given user is not
+// counted, or an empty string if the approval is eligible.
+func (ae *approvalEligibility) ineligibleReason(ctx context.Context, user *gh.User) (string, error) {
+	if isBot(user) {
+		return "bot account", nil
+	}
+	if ae.cfg == nil {
+		return "", nil
+	}
+	login := strings.ToLower(user.GetLogin())
+
+	if ae.cfg.ExcludeCommitAuthors {
+		if ae.commitAuthors == nil {
+			var err error
+			ae.commitAuthors, err = ae.c.getCommitAuthors(ctx, ae.owner, ae.repoName, ae.prNumber)
+			if err != nil {
+				return "", err
+			}
+		}
+		if _, ok := ae.commitAuthors[login]; ok {
+			return "commit author", nil
+		}
+	}
+
+	// Without any allowed users, teams or permission all non-bot approvals
+	// are counted.
+	if ae.cfg.Permission == "" && len(ae.cfg.Teams) == 0 && len(ae.cfg.Users) == 0 {
+		return "", nil
+	}
+
+	for _, u := range ae.cfg.Users {
+		if strings.EqualFold(u, login) {
+			return "", nil
+		}
+	}
+
+	approvers := map[string]struct{}{login: {}}
+	for _, team := range ae.cfg.Teams {
+		org, slug, ok := strings.Cut(team, "/")
+		if !ok {
+			org, slug = ae.owner, team
+		}
+		ok, err := ae.c.approvedByTeam(ctx, org, slug, approvers)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return "", nil
+		}
+	}
+
+	if ae.cfg.Permission != "" {
+		minRank, ok := permissionRanks[ae.cfg.Permission]
+		if !ok {
+			return "", fmt.Errorf("unknown permission %q", ae.cfg.Permission)
+		}
+		perm, _, err := ae.c.GHClient.Repositories.GetPermissionLevel(ctx, ae.owner, ae.repoName, user.GetLogin())
+		if err != nil && !IsNotFound(err) {
+			return "", err
+		}
+		role := perm.GetRoleName()
+		if _, ok := permissionRanks[role]; !ok {
+			role = perm.GetPermission()
+		}
+		if role == "" {
+			role = "none"
+		}
+		if permissionRanks[role] >= minRank {
+			return "", nil
+		}
+		return fmt.Sprintf("%q permission", role), nil
+	}
+
+	return "not an allowed user or team member", nil
+}
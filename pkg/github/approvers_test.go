@@ -0,0 +1,249 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	gh "github.com/google/go-github/v84/github"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestApproversConfig_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		permission string
+		wantErr    bool
+	}{
+		{permission: ""},
+		{permission: "read"},
+		{permission: "triage"},
+		{permission: "write"},
+		{permission: "maintain"},
+		{permission: "admin"},
+		{permission: "none", wantErr: true},
+		{permission: "push", wantErr: true},
+		{permission: "Write", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.permission, func(t *testing.T) {
+			var got ApproversConfig
+			err := yaml.Unmarshal([]byte("permission: '"+tt.permission+"'\nusers: [aanm]"), &got)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "unknown permission")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, ApproversConfig{Permission: tt.permission, Users: []string{"aanm"}}, got)
+		})
+	}
+}
+
+func Test_isBot(t *testing.T) {
+	tests := []struct {
+		name string
+		user *gh.User
+		want bool
+	}{
+		{name: "user", user: &gh.User{Login: new("aanm"), Type: new("User")}, want: false},
+		{name: "bot type", user: &gh.User{Login: new("renovate"), Type: new("Bot")}, want: true},
+		{name: "bot suffix", user: &gh.User{Login: new("dependabot[bot]")}, want: true},
+		{name: "unknown user", user: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isBot(tt.user))
+		})
+	}
+}
+
+func Test_coAuthorNoReplyRegexp(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{
+			name:    "no trailers",
+			message: "Fix the bug\n\nSigned-off-by: Alice <alice@example.com>",
+			want:    nil,
+		},
+		{
+			name:    "no-reply addresses with and without ID",
+			message: "Fix the bug\n\nCo-authored-by: Bob <12345+bob@users.noreply.github.com>\nco-authored-by: Carol <Carol@users.noreply.github.com>",
+			want:    []string{"bob", "Carol"},
+		},
+		{
+			name:    "other addresses",
+			message: "Fix the bug\n\nCo-authored-by: Dave <dave@example.com>",
+			want:    nil,
+		},
+		{
+			name:    "not a trailer",
+			message: "Fix the bug reported in Co-authored-by: Bob <bob@users.noreply.github.com>",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range coAuthorNoReplyRegexp.FindAllStringSubmatch(tt.message, -1) {
+				got = append(got, m[1])
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApprovalEligibility_ineligibleReason(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *ApproversConfig
+		user string
+		want string
+	}{
+		{
+			name: "bot without configuration",
+			user: "dependabot[bot]",
+			want: "bot account",
+		},
+		{
+			name: "user without configuration",
+			user: "dave",
+			want: "",
+		},
+		{
+			name: "commit author",
+			cfg:  &ApproversConfig{ExcludeCommitAuthors: true},
+			user: "alice",
+			want: "commit author",
+		},
+		{
+			name: "commit co-author",
+			cfg:  &ApproversConfig{ExcludeCommitAuthors: true},
+			user: "Bob",
+			want: "commit author",
+		},
+		{
+			name: "not a commit author",
+			cfg:  &ApproversConfig{ExcludeCommitAuthors: true},
+			user: "dave",
+			want: "",
+		},
+		{
+			name: "allowed user",
+			cfg:  &ApproversConfig{Users: []string{"Dave"}, Teams: []string{"committers"}},
+			user: "dave",
+			want: "",
+		},
+		{
+			name: "team member",
+			cfg:  &ApproversConfig{Teams: []string{"cilium/committers"}},
+			user: "carol",
+			want: "",
+		},
+		{
+			name: "team of the repository owner",
+			cfg:  &ApproversConfig{Teams: []string{"committers"}},
+			user: "carol",
+			want: "",
+		},
+		{
+			name: "neither allowed user nor team member",
+			cfg:  &ApproversConfig{Users: []string{"alice"}, Teams: []string{"committers"}},
+			user: "dave",
+			want: "not an allowed user or team member",
+		},
+		{
+			name: "same permission",
+			cfg:  &ApproversConfig{Permission: "write"},
+			user: "erin",
+			want: "",
+		},
+		{
+			name: "higher permission",
+			cfg:  &ApproversConfig{Permission: "triage"},
+			user: "erin",
+			want: "",
+		},
+		{
+			name: "lower permission",
+			cfg:  &ApproversConfig{Permission: "maintain"},
+			user: "erin",
+			want: `"write" permission`,
+		},
+		{
+			name: "no permission",
+			cfg:  &ApproversConfig{Permission: "read"},
+			user: "dave",
+			want: `"none" permission`,
+		},
+		{
+			name: "team member without permission",
+			cfg:  &ApproversConfig{Permission: "admin", Teams: []string{"committers"}},
+			user: "carol",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			srv.AddPR("cilium", "cilium", githubtest.PR{
+				Commits: []githubtest.Commit{{
+					SHA:     "c1",
+					Author:  "alice",
+					Message: "Fix the bug\n\nCo-authored-by: Bob <123+bob@users.noreply.github.com>",
+				}},
+			})
+			srv.SetTeamMembers("cilium", "committers", "carol")
+			srv.SetPermission("cilium", "cilium", "erin", "write")
+			ae := &approvalEligibility{
+				c:        c,
+				cfg:      tt.cfg,
+				owner:    "cilium",
+				repoName: "cilium",
+				prNumber: 1,
+			}
+
+			got, err := ae.ineligibleReason(context.Background(), &gh.User{Login: new(tt.user)})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_autoMergeEval_approversFromBase(t *testing.T) {
+	srv, c := newFakeClient(t)
+	setFakeConfig(t, srv, "auto-merge:\n  enabled: true\n  approvers:\n    users: [bob]\n")
+	// A PR must not be able to relax the approvers through its own head.
+	srv.SetFileAt("cilium", "cilium", "abc", testConfigPath, "auto-merge:\n  enabled: true\n  approvers:\n    users: [mallory]\n")
+	srv.SetBranchProtection("cilium", "cilium", "main", "ci/build")
+	srv.AddPR("cilium", "cilium", githubtest.PR{
+		Number:  1,
+		Author:  "alice",
+		BaseSHA: "base",
+		Commits: []githubtest.Commit{{SHA: "abc", Author: "alice"}},
+	})
+	srv.SetStatus("cilium", "cilium", "abc", "ci/build", "success")
+	srv.AddReview("cilium", "cilium", 1, "mallory", "APPROVED", "abc")
+
+	err := autoMergeEval(1)(c)
+	assert.NoError(t, err)
+	assert.Empty(t, srv.Labels("cilium", "cilium", 1))
+	assert.Equal(t, "Waiting for reviews", srv.CheckRun("cilium", "cilium", "abc", autoMergeStatusCheckName).GetOutput().GetTitle())
+	assert.False(t, srv.Merged("cilium", "cilium", 1))
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// The rules of PRBlockerConfig that cause mutations, as recorded in the audit
+// log.
+const (
+	ruleProject             = "project"
+	ruleMoveToProjects      = "move-to-projects-for-labels-xored"
+	ruleRequireMsgsInCommit = "require-msgs-in-commit"
+	ruleAutoLabel           = "auto-label"
+	ruleBlockPRWith         = "block-pr-with"
+	ruleAutoMerge           = "auto-merge"
+	ruleFlakeTracker        = "flake-tracker"
+)
+
+// AuditRecord is a mutation issued by a client.
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// DeliveryID is the ID of the webhook delivery that triggered the
+	// mutation, if any.
+	DeliveryID string `json:"delivery-id,omitempty"`
+	Owner      string `json:"owner"`
+	Repo       string `json:"repo"`
+	// Number is the number of the PR or issue, if any.
+	Number int    `json:"number,omitempty"`
+	Action string `json:"action"`
+	// Rule is the rule of the config that caused the mutation.
+	Rule string                 `json:"rule"`
+	Args map[string]interface{} `json:"args,omitempty"`
+	// Shadow is true if the mutation was only planned, in shadow mode.
+	Shadow bool `json:"shadow,omitempty"`
+	// Error is the error returned by GitHub, if the mutation failed.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditLog is an append-only log of the mutations issued by the clients,
+// stored as one JSON record per line. It is safe for concurrent use.
+type AuditLog struct {
+	path string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenAuditLog opens the audit log stored in the given file, creating it if
+// it does not exist.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log: %w", err)
+	}
+	return &AuditLog{path: path, f: f}, nil
+}
+
+// Append appends the record to the log.
+func (l *AuditLog) Append(r AuditRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.f.Write(append(b, '\n'))
+	return err
+}
+
+// Query returns the records of the given repository, and of the given PR or
+// issue if number is not 0, from the oldest to the most recent. Invalid
+// records are logged with the logger of the context and skipped.
+func (l *AuditLog) Query(ctx context.Context, owner, repoName string, number int) ([]AuditRecord, error) {
+	// Only read the records fully written when the query started, so that we
+	// never read a partially written record without holding the lock while
+	// reading the whole log.
+	l.mu.Lock()
+	fi, err := l.f.Stat()
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := []AuditRecord{}
+	scanner := bufio.NewScanner(io.LimitReader(f, fi.Size()))
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var r AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			zerolog.Ctx(ctx).Err(err).Fields(map[string]interface{}{
+				"path": l.path,
+				"line": line,
+			}).Msg("Ignoring invalid audit record")
+			continue
+		}
+		if r.Owner != owner || r.Repo != repoName || (number != 0 && r.Number != number) {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
+
+// Close closes the log.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// SetAuditLog sets the log where all mutations issued by the client are
+// recorded, along with the ID of the webhook delivery that triggered them.
+func (c *Client) SetAuditLog(l *AuditLog, deliveryID string) {
+	c.auditLog = l
+	c.deliveryID = deliveryID
+}
+
+// audit records the mutation in the audit log, if any.
+func (c *Client) audit(r AuditRecord) {
+	if c.auditLog == nil {
+		return
+	}
+	r.Time = time.Now()
+	r.DeliveryID = c.deliveryID
+	if err := c.auditLog.Append(r); err != nil {
+		c.log.Err(err).Fields(map[string]interface{}{
+			"action": r.Action,
+			"number": r.Number,
+		}).Msg("Unable to record mutation in audit log")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog(t *testing.T) {
+	l, err := OpenAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+
+	log := zerolog.Nop()
+	c := NewClientFromGHClient(nil, nil, "cilium", "cilium", &log)
+	c.SetAuditLog(l, "delivery-1")
+
+	ctx := context.Background()
+	err = c.mutate(ruleAutoMerge, "add-labels", "cilium", "cilium", 1, map[string]interface{}{"label": "ready-to-merge"}, func() error {
+		return nil
+	})
+	assert.NoError(t, err)
+	err = c.mutate(ruleBlockPRWith, "remove-label", "cilium", "cilium", 2, nil, func() error {
+		return errors.New("not found")
+	})
+	assert.Error(t, err)
+	c.SetShadowMode(true)
+	assert.NoError(t, c.createComment(ctx, ruleFlakeTracker, "cilium", "tetragon", 1, "hello"))
+
+	records, err := l.Query(ctx, "cilium", "cilium", 0)
+	assert.NoError(t, err)
+	if !assert.Len(t, records, 2) {
+		return
+	}
+	assert.Equal(t, "delivery-1", records[0].DeliveryID)
+	assert.Equal(t, ruleAutoMerge, records[0].Rule)
+	assert.Equal(t, map[string]interface{}{"label": "ready-to-merge"}, records[0].Args)
+	assert.False(t, records[0].Time.IsZero())
+	assert.Equal(t, "not found", records[1].Error)
+
+	records, err = l.Query(ctx, "cilium", "tetragon", 1)
+	assert.NoError(t, err)
+	if !assert.Len(t, records, 1) {
+		return
+	}
+	assert.Equal(t, "create-comment", records[0].Action)
+	assert.True(t, records[0].Shadow)
+
+	records, err = l.Query(ctx, "cilium", "cilium", 3)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestAuditLog_Query_invalidRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// A record truncated by a crash.
+	assert.NoError(t, os.WriteFile(path, []byte(`{"owner":"cilium","repo":"cilium","number":1,"act`+"\n"), 0o644))
+	l, err := OpenAuditLog(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+
+	assert.NoError(t, l.Append(AuditRecord{Owner: "cilium", Repo: "cilium", Number: 1, Action: "add-labels"}))
+	records, err := l.Query(context.Background(), "cilium", "cilium", 1)
+	assert.NoError(t, err)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "add-labels", records[0].Action)
+	}
+}
@@ -17,17 +17,147 @@ package github
 import (
 	"context"
 	"fmt"
+	"maps"
+	"path"
+	"slices"
 	"strings"
 	"time"
 
 	gh "github.com/google/go-github/v84/github"
 )
 
+const (
+	defaultAutoMergeLabel            = "ready-to-merge"
+	defaultAutoMergeMinimalApprovals = 1
+)
+
 type AutoMerge struct {
-	Label            string `yaml:"label"`
-	MinimalApprovals int    `yaml:"min-approvals"`
+	// Enabled enables the auto-merge logic. If the 'auto-merge' section is
+	// missing from the configuration or if Enabled is false, PRs are never
+	// labeled as ready to merge.
+	Enabled bool `yaml:"enabled"`
+	// Label is the label set in the PR once all the conditions to merge the
+	// PR are met. Defaults to "ready-to-merge".
+	Label string `yaml:"label,omitempty"`
+	// MinimalApprovals is the number of approvals required to consider the
+	// PR as ready to merge. Defaults to 1.
+	MinimalApprovals int `yaml:"min-approvals,omitempty"`
+	// RequireCodeOwners requires, for each group of code owners of the files
+	// changed by the PR, an approval from at least one of the users or team
+	// members of that group. Code owners are read from the CODEOWNERS file
+	// at the base SHA of the PR. The groups without approval are listed in
+	// the Auto-merge status check, and in the Mergeability check if the PR
+	// has one, i.e., if BlockPRWith or MoveToProjectsForLabelsXORed is
+	// configured.
+	RequireCodeOwners bool `yaml:"require-code-owners,omitempty"`
+	// RequireResolvedThreads requires all review threads of the PR, that are
+	// not outdated, to be resolved.
+	RequireResolvedThreads bool `yaml:"require-resolved-threads,omitempty"`
+	// PassingConclusions are the check run conclusions, besides "success",
+	// that satisfy a required check, e.g., "neutral" or "skipped". Defaults
+	// to "skipped".
+	PassingConclusions []string `yaml:"passing-conclusions,omitempty"`
+	// Approvers, if set, restricts which approvals are counted. Approvals
+	// from bot accounts are never counted.
+	Approvers *ApproversConfig `yaml:"approvers,omitempty"`
+	// InvalidateStaleApprovals does not count approvals done on changes that
+	// differ from the current head of the PR. Rebases alone, without other
+	// changes, never make reviews stale.
+	InvalidateStaleApprovals bool `yaml:"invalidate-stale-approvals,omitempty"`
+	// Merge, if set, merges the PR, or enables GitHub's native auto-merge,
+	// once all the conditions are met and the Mergeability check is not
+	// blocking the PR. By default, PRs are only labeled.
+	Merge *MergeConfig `yaml:"merge,omitempty"`
+	// Branches overrides the settings above for PRs that target a base
+	// branch matching AutoMergeBranch.Branch. The first match wins.
+	Branches []AutoMergeBranch `yaml:"branches,omitempty"`
 }
 
+type AutoMergeBranch struct {
+	// Branch is a glob pattern, as supported by path.Match, matched against
+	// the base branch of the PR.
+	Branch string `yaml:"branch"`
+	// Enabled, if set, overrides AutoMerge.Enabled for this branch.
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Label, if set, overrides AutoMerge.Label for this branch.
+	Label string `yaml:"label,omitempty"`
+	// MinimalApprovals, if set, overrides AutoMerge.MinimalApprovals for this
+	// branch.
+	MinimalApprovals int `yaml:"min-approvals,omitempty"`
+	// Merge, if set, overrides AutoMerge.Merge for this branch.
+	Merge *MergeConfig `yaml:"merge,omitempty"`
+}
+
+// UnmarshalYAML validates the branch patterns once parsed so that a malformed
+// pattern is reported when the configuration is loaded rather than silently
+// never matching.
+func (am *AutoMerge) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain AutoMerge
+	if err := unmarshal((*plain)(am)); err != nil {
+		return err
+	}
+	for _, br := range am.Branches {
+		if _, err := path.Match(br.Branch, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern %q: %w", br.Branch, err)
+		}
+	}
+	return nil
+}
+
+// ForBranch returns the auto-merge configuration that applies to PRs
+// targeting the given base branch, with the defaults filled in. It returns
+// false if auto-merge is disabled for that branch.
+func (am *AutoMerge) ForBranch(branch string) (AutoMerge, bool) {
+	if am == nil {
+		return AutoMerge{}, false
+	}
+
+	cfg := AutoMerge{
+		Enabled:                  am.Enabled,
+		Label:                    am.Label,
+		MinimalApprovals:         am.MinimalApprovals,
+		RequireCodeOwners:        am.RequireCodeOwners,
+		RequireResolvedThreads:   am.RequireResolvedThreads,
+		PassingConclusions:       am.PassingConclusions,
+		Approvers:                am.Approvers,
+		InvalidateStaleApprovals: am.InvalidateStaleApprovals,
+		Merge:                    am.Merge,
+	}
+	for _, br := range am.Branches {
+		// The patterns are validated when the configuration is parsed.
+		matched, _ := path.Match(br.Branch, branch)
+		if !matched {
+			continue
+		}
+		if br.Enabled != nil {
+			cfg.Enabled = *br.Enabled
+		}
+		if br.Label != "" {
+			cfg.Label = br.Label
+		}
+		if br.MinimalApprovals != 0 {
+			cfg.MinimalApprovals = br.MinimalApprovals
+		}
+		if br.Merge != nil {
+			cfg.Merge = br.Merge
+		}
+		break
+	}
+
+	if cfg.Label == "" {
+		cfg.Label = defaultAutoMergeLabel
+	}
+	if cfg.MinimalApprovals == 0 {
+		cfg.MinimalApprovals = defaultAutoMergeMinimalApprovals
+	}
+	if cfg.PassingConclusions == nil {
+		cfg.PassingConclusions = defaultPassingConclusions
+	}
+	return cfg, cfg.Enabled
+}
+
+// AutoMerge evaluates the auto-merge conditions of the PR, sets or removes
+// the auto-merge label and updates the "Auto-merge status" check run.
 func (c *Client) AutoMerge(
 	cfg AutoMerge,
 	owner, repoName string,
@@ -37,20 +167,50 @@ func (c *Client) AutoMerge(
 	prLabels PRLabels,
 	review *gh.PullRequestReview,
 ) error {
+	_, err := c.autoMerge(cfg, owner, repoName, base, head, prNumber, prLabels, review)
+	return err
+}
+
+// autoMerge is AutoMerge but it also returns the outcome of the evaluation,
+// which is nil if the head commit has no committer date.
+func (c *Client) autoMerge(
+	cfg AutoMerge,
+	owner, repoName string,
+	base,
+	head *gh.PullRequestBranch,
+	prNumber int,
+	prLabels PRLabels,
+	review *gh.PullRequestReview,
+) (*autoMergeStatus, error) {
 
-	ciChecks, err := c.getCIStatus(owner, repoName, base, head, prNumber)
+	ciChecks, err := c.getCIStatus(owner, repoName, base, head, prNumber, cfg.PassingConclusions)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if c.ciDebouncer != nil {
+		c.ciDebouncer.setRemaining(commitKey(owner, repoName, head.GetSHA()), ciChecks)
+	}
+	err = c.SetMergeabilitySection(owner, repoName, prNumber, head.GetSHA(), "Required checks not passed", ciChecks.Strings())
+	if err != nil {
+		return nil, err
 	}
 
 	if len(ciChecks) != 0 {
+		msg := "Not auto merging because ci is still running"
+		if ciChecks.Broken() {
+			msg = "Not auto merging because ci failed"
+		}
 		c.log.Info().Fields(map[string]interface{}{
 			"owner":     owner,
 			"repo":      repoName,
 			"pr-number": prNumber,
-			"ci-checks": ciChecks,
-		}).Msg("Not auto merging because ci failed")
-		return nil
+			"ci-checks": ciChecks.Strings(),
+		}).Msg(msg)
+		status := &autoMergeStatus{
+			Label:    cfg.Label,
+			CIChecks: ciChecks,
+		}
+		return status, c.updateAutoMergeStatus(owner, repoName, prNumber, head.GetSHA(), status)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -58,7 +218,7 @@ func (c *Client) AutoMerge(
 
 	commit, _, err := c.GHClient.Repositories.GetCommit(ctx, owner, repoName, head.GetSHA(), &gh.ListOptions{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	commitDate := commit.GetCommit().GetCommitter().GetDate()
@@ -71,13 +231,14 @@ func (c *Client) AutoMerge(
 			"commit":      gh.Stringify(commit.GetCommit()),
 			"full-commit": gh.Stringify(commit),
 		}).Msg("Not auto merging because of empty-committer")
-		return nil
+		c.metrics.autoMergeEvaluated("empty-committer")
+		return nil, nil
 	}
 
 	// If the CI have passed, check all reviews
 	userReviews, err := c.getReviews(owner, repoName, prNumber)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if review != nil {
 		// We have received a review event. We have the most updated review
@@ -90,16 +251,36 @@ func (c *Client) AutoMerge(
 	var (
 		requestedReviews     []string
 		approvals            int
+		approvers            = map[string]struct{}{}
+		ineligibleApprovals  = map[string]string{}
+		staleApprovals       []string
 		userChangesRequested = map[string]struct{}{}
+		stale                = staleReviews{
+			c:        c,
+			owner:    owner,
+			repoName: repoName,
+			baseRef:  base.GetRef(),
+			headSHA:  head.GetSHA(),
+			headDate: commitDate.Time,
+		}
+		eligibility = approvalEligibility{
+			c:        c,
+			cfg:      cfg.Approvers,
+			owner:    owner,
+			repoName: repoName,
+			prNumber: prNumber,
+		}
 	)
+	reviewsCtx, reviewsCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer reviewsCancel()
 	for _, userReview := range userReviews {
 		// request reviews for users that have stale reviews
-		// (stale review is a review that was done before the PR was resynced
-		// by the author)
+		// (stale review is a review that was done on changes that differ
+		// from the ones at the head of the PR)
 
 		switch strings.ToLower(userReview.GetState()) {
 		case "changes_requested":
-			if userReview.SubmittedAt.Before(commitDate.Time) {
+			if stale.isStale(reviewsCtx, userReview) {
 				requestedReviews = append(
 					requestedReviews,
 					userReview.GetUser().GetLogin(),
@@ -108,7 +289,22 @@ func (c *Client) AutoMerge(
 				userChangesRequested[userReview.GetUser().GetLogin()] = struct{}{}
 			}
 		case "approve", "approved":
+			reason, err := eligibility.ineligibleReason(reviewsCtx, userReview.GetUser())
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				ineligibleApprovals[userReview.GetUser().GetLogin()] = reason
+				continue
+			}
+			if cfg.InvalidateStaleApprovals {
+				if stale.isStale(reviewsCtx, userReview) {
+					staleApprovals = append(staleApprovals, userReview.GetUser().GetLogin())
+					continue
+				}
+			}
 			approvals++
+			approvers[strings.ToLower(userReview.GetUser().GetLogin())] = struct{}{}
 		}
 	}
 	if len(requestedReviews) != 0 {
@@ -119,16 +315,35 @@ func (c *Client) AutoMerge(
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		_, _, err = c.GHClient.PullRequests.RequestReviewers(ctx, owner, repoName, prNumber, gh.ReviewersRequest{
-			Reviewers: requestedReviews,
-		})
+		err = c.requestReviewers(ctx, ruleAutoMerge, owner, repoName, prNumber, requestedReviews)
+		if err != nil {
+			return nil, err
+		}
 		// We don't continue if we just have requested for new reviews
-		return err
+		status := &autoMergeStatus{
+			Label:            cfg.Label,
+			ReviewsRequested: requestedReviews,
+		}
+		return status, c.updateAutoMergeStatus(owner, repoName, prNumber, head.GetSHA(), status)
 	}
 	// Check if we still have pending reviewers
 	users, teams, err := c.getPendingReviews(owner, repoName, prNumber)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	// GitHub keeps a team as a requested reviewer even after one of its
+	// members has approved the PR on behalf of the team, so consider the
+	// team review satisfied if any of its current members approved.
+	for team := range teams {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		ok, err := c.approvedByTeam(ctx, owner, team, approvers)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			delete(teams, team)
+		}
 	}
 	// If the user has requested for changes we can delete them from here
 	// because we are already waiting for a review from them.
@@ -149,45 +364,84 @@ func (c *Client) AutoMerge(
 		}
 	}
 
-	if approvals < cfg.MinimalApprovals || len(users) != 0 || len(teams) != 0 || len(userChangesRequested) != 0 {
+	var unsatisfiedCodeOwners []string
+	if cfg.RequireCodeOwners {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		unsatisfiedCodeOwners, err = c.unsatisfiedCodeOwners(ctx, owner, repoName, base.GetSHA(), prNumber, approvers)
+		if err != nil {
+			return nil, err
+		}
+		err = c.SetMergeabilitySection(owner, repoName, prNumber, head.GetSHA(), "Code owners without approval", unsatisfiedCodeOwners)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var unresolvedThreads []string
+	if cfg.RequireResolvedThreads {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		unresolvedThreads, err = c.getUnresolvedThreads(ctx, owner, repoName, prNumber)
+		if err != nil {
+			return nil, err
+		}
+		err = c.SetMergeabilitySection(owner, repoName, prNumber, head.GetSHA(), "Unresolved review threads", unresolvedThreads)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pendingTeams []string
+	for _, team := range slices.Sorted(maps.Keys(teams)) {
+		pendingTeams = append(pendingTeams, owner+"/"+team)
+	}
+	status := &autoMergeStatus{
+		Label:                 cfg.Label,
+		Approvals:             approvals,
+		MinimalApprovals:      cfg.MinimalApprovals,
+		IneligibleApprovals:   ineligibleApprovals,
+		StaleApprovals:        staleApprovals,
+		PendingUsers:          slices.Sorted(maps.Keys(users)),
+		PendingTeams:          pendingTeams,
+		ChangesRequested:      slices.Sorted(maps.Keys(userChangesRequested)),
+		UnsatisfiedCodeOwners: unsatisfiedCodeOwners,
+		UnresolvedThreads:     unresolvedThreads,
+	}
+	if !status.ready() {
 		c.log.Info().Fields(map[string]interface{}{
+			"code-owners-unsatisfied": unsatisfiedCodeOwners,
+			"unresolved-threads":      unresolvedThreads,
 			"teams":                   teams,
 			"users":                   users,
 			"users-requested-changes": userChangesRequested,
 			"min-approvals":           cfg.MinimalApprovals,
 			"total-approvals":         approvals,
+			"ineligible-approvals":    ineligibleApprovals,
+			"stale-approvals":         staleApprovals,
 			"pr-number":               prNumber,
 		}).Msg("Users have requested changes, the author hasn't synced the PR or the PR does not have the minimal approvals")
 		// Only review the label if we know that exists or that we are handling
 		// a PR review event (review != nil).
 		if _, ok := prLabels[cfg.Label]; ok || review != nil {
-			c.log.Info().Msg("Removing ready-to-merge label")
-			_, err := c.GHClient.Issues.RemoveLabelForIssue(
-				context.Background(), owner, repoName, prNumber, cfg.Label)
+			c.log.Info().Fields(map[string]interface{}{
+				"label":     cfg.Label,
+				"pr-number": prNumber,
+			}).Msg("Removing auto-merge label")
+			err := c.removeLabel(context.Background(), ruleAutoMerge, owner, repoName, prNumber, cfg.Label)
 			if err != nil && !IsNotFound(err) {
-				return err
+				return nil, err
 			}
 			delete(prLabels, cfg.Label)
 		}
-		return nil
-	}
-
-	if false {
-		ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		_, _, err = c.GHClient.Issues.CreateComment(ctx, owner, repoName, prNumber, &gh.IssueComment{
-			Body: func() *string { ; return new(fmt.Sprintf("Setting %s to let a human merge this PR.", cfg.Label)) }(),
-		})
-		if err != nil {
-			return err
-		}
+		return status, c.updateAutoMergeStatus(owner, repoName, prNumber, head.GetSHA(), status)
 	}
 
 	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	_, _, err = c.GHClient.Issues.AddLabelsToIssue(ctx, owner, repoName, prNumber, []string{cfg.Label})
+	err = c.addLabels(ctx, ruleAutoMerge, owner, repoName, prNumber, []string{cfg.Label})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	c.log.Info().Fields(map[string]interface{}{
 		"teams":                   teams,
@@ -195,19 +449,34 @@ func (c *Client) AutoMerge(
 		"users-requested-changes": userChangesRequested,
 		"min-approvals":           cfg.MinimalApprovals,
 		"total-approvals":         approvals,
+		"ineligible-approvals":    ineligibleApprovals,
+		"stale-approvals":         staleApprovals,
 		"pr-number":               prNumber,
-	}).Msg("Set 'ready-to-merge'")
+		"label":                   cfg.Label,
+	}).Msg("Set auto-merge label")
 	prLabels[cfg.Label] = struct{}{}
 
-	return nil
+	err = c.updateAutoMergeStatus(owner, repoName, prNumber, head.GetSHA(), status)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Merge != nil {
+		return status, c.MergePR(*cfg.Merge, owner, repoName, prNumber, head.GetSHA())
+	}
+
+	return status, nil
 }
 
-// getCIStatus returns a list of CI Checks that are not successful.
+// getCIStatus returns the required CI checks that did not pass in the head of
+// the PR. Besides "success", only the check run conclusions in
+// 'passingConclusions' satisfy a required check.
 func (c *Client) getCIStatus(
 	owner, repoName string,
 	base,
 	head *gh.PullRequestBranch,
-	prNumber int) ([]string, error) {
+	prNumber int,
+	passingConclusions []string) (CIChecks, error) {
 
 	var (
 		cancels []context.CancelFunc
@@ -220,34 +489,77 @@ func (c *Client) getCIStatus(
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	cancels = append(cancels, cancel)
-	nextPage := 0
 
-	brProt, _, err := c.GHClient.Repositories.GetBranchProtection(ctx, owner, repoName, base.GetRef())
+	requiredContexts, err := c.getRequiredChecks(ctx, owner, repoName, base.GetRef())
 	if err != nil {
 		return nil, err
 	}
-	requiredContexts := map[string]struct{}{}
-	for _, ctx := range *brProt.GetRequiredStatusChecks().Contexts {
-		requiredContexts[ctx] = struct{}{}
+	// Our own check runs are not CI checks, even if they are required: the
+	// Mergeability check is enforced by MergePR and the Auto-merge status
+	// check reports the outcome of this very evaluation.
+	for _, name := range []string{mergeabilityCheckName, autoMergeStatusCheckName} {
+		if appID, ok := requiredContexts[name]; ok && (appID == anyApp || appID == c.appID) {
+			delete(requiredContexts, name)
+		}
+	}
+	if len(requiredContexts) == 0 {
+		return nil, nil
+	}
+
+	results, err := c.getCIResults(ctx, owner, repoName, head.GetSHA(), requiredContexts, passingConclusions)
+	if err != nil {
+		return nil, err
+	}
+	ciChecks := results.checks(requiredContexts)
+
+	// Required checks that were never reported might have been reported for
+	// the previous commit of the PR, e.g., if the CI was not re-triggered
+	// after a push.
+	var missing bool
+	for _, chk := range ciChecks {
+		missing = missing || chk.State == CIStateMissing
+	}
+	if !missing {
+		return ciChecks, nil
+	}
+	prevSHA, err := c.getPreviousPRCommit(ctx, owner, repoName, prNumber, head.GetSHA())
+	if err != nil || prevSHA == "" {
+		return ciChecks, err
 	}
+	prevResults, err := c.getCIResults(ctx, owner, repoName, prevSHA, requiredContexts, passingConclusions)
+	if err != nil {
+		return nil, err
+	}
+	for i, chk := range ciChecks {
+		if prev, ok := prevResults[chk.Name]; ok && chk.State == CIStateMissing {
+			ciChecks[i] = CICheck{Name: chk.Name, State: CIStateStale, URL: prev.URL}
+		}
+	}
+	return ciChecks, nil
+}
 
-	passedContexts := map[string]bool{}
+// getCIResults returns the states of the required checks reported for the
+// given SHA, both as commit statuses and as check runs.
+func (c *Client) getCIResults(ctx context.Context, owner, repoName, sha string, requiredContexts requiredChecks, passingConclusions []string) (ciResults, error) {
+	results := ciResults{}
 
+	nextPage := 0
 	for {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		cancels = append(cancels, cancel)
-		gs, resp, err := c.GHClient.Repositories.GetCombinedStatus(ctx, owner, repoName, head.GetSHA(), &gh.ListOptions{
+		gs, resp, err := c.GHClient.Repositories.GetCombinedStatus(ctx, owner, repoName, sha, &gh.ListOptions{
 			Page: nextPage,
 		})
 		if err != nil {
 			return nil, err
 		}
 		for _, statuses := range gs.Statuses {
-			if statuses.GetState() == "success" {
-				if _, ok := passedContexts[statuses.GetContext()]; !ok {
-					passedContexts[statuses.GetContext()] = true
-				}
+			// Commit statuses do not expose which GitHub App created them
+			// so only required contexts not pinned to an app are satisfied
+			// by them.
+			appID, ok := requiredContexts[statuses.GetContext()]
+			if !ok || appID != anyApp {
+				continue
 			}
+			results.add(statuses.GetContext(), commitStatusState(statuses.GetState()), statuses.GetTargetURL())
 		}
 		nextPage = resp.NextPage
 		if nextPage != 0 {
@@ -258,7 +570,7 @@ func (c *Client) getCIStatus(
 
 	nextPage = 0
 	for {
-		lc, resp, err := c.GHClient.Checks.ListCheckRunsForRef(ctx, owner, repoName, head.GetSHA(), &gh.ListCheckRunsOptions{
+		lc, resp, err := c.GHClient.Checks.ListCheckRunsForRef(ctx, owner, repoName, sha, &gh.ListCheckRunsOptions{
 			ListOptions: gh.ListOptions{
 				Page: nextPage,
 			},
@@ -267,13 +579,13 @@ func (c *Client) getCIStatus(
 			return nil, err
 		}
 		for _, cr := range lc.CheckRuns {
-			if cr.GetConclusion() == "success" || cr.GetConclusion() == "skipped" {
-				if _, ok := passedContexts[cr.GetName()]; !ok {
-					passedContexts[cr.GetName()] = true
-				}
-			} else {
-				passedContexts[cr.GetName()] = false
+			// Ignore check runs from other apps if the required check is
+			// pinned to a specific app, and our own check runs.
+			appID, ok := requiredContexts[cr.GetName()]
+			if !ok || (appID != anyApp && appID != cr.GetApp().GetID()) || c.isOwnCheckRun(cr) {
+				continue
 			}
+			results.add(cr.GetName(), checkRunState(cr.GetStatus(), cr.GetConclusion(), passingConclusions), cr.GetHTMLURL())
 		}
 
 		nextPage = resp.NextPage
@@ -284,19 +596,33 @@ func (c *Client) getCIStatus(
 		break
 	}
 
-	for reqCtxName := range requiredContexts {
-		// Remove the contexts that have passed from the requiredContexts
-		if passedContexts[reqCtxName] {
-			delete(requiredContexts, reqCtxName)
-		}
-	}
+	return results, nil
+}
 
-	ciChecks := make([]string, 0, len(requiredContexts))
-	for reqCtxName := range requiredContexts {
-		ciChecks = append(ciChecks, reqCtxName)
+// getPreviousPRCommit returns the commit of the PR that precedes the given
+// head SHA, or an empty string if the PR has a single commit.
+func (c *Client) getPreviousPRCommit(ctx context.Context, owner, repoName string, prNumber int, headSHA string) (string, error) {
+	var prev string
+	nextPage := 0
+	for {
+		commits, resp, err := c.GHClient.PullRequests.ListCommits(ctx, owner, repoName, prNumber, &gh.ListOptions{
+			Page:    nextPage,
+			PerPage: 100,
+		})
+		if err != nil {
+			return "", err
+		}
+		for _, commit := range commits {
+			if commit.GetSHA() == headSHA {
+				return prev, nil
+			}
+			prev = commit.GetSHA()
+		}
+		nextPage = resp.NextPage
+		if nextPage == 0 {
+			return "", nil
+		}
 	}
-
-	return ciChecks, nil
 }
 
 func addReview(recentReviewsByUser map[string]*gh.PullRequestReview, review *gh.PullRequestReview) {
@@ -373,7 +699,7 @@ func (c *Client) getPendingReviews(owner string, repoName string, prNumber int)
 			users[user.GetLogin()] = struct{}{}
 		}
 		for _, team := range reviewers.Teams {
-			teams[team.GetName()] = struct{}{}
+			teams[team.GetSlug()] = struct{}{}
 		}
 
 	}
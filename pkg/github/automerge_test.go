@@ -0,0 +1,246 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	gh "github.com/google/go-github/v84/github"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestAutoMerge_ForBranch(t *testing.T) {
+	cfg := &AutoMerge{
+		Enabled: true,
+		Branches: []AutoMergeBranch{
+			{
+				Branch:           "v*",
+				MinimalApprovals: 2,
+			},
+			{
+				Branch:  "ft/*",
+				Enabled: new(false),
+			},
+		},
+	}
+	tests := []struct {
+		name        string
+		cfg         *AutoMerge
+		branch      string
+		want        AutoMerge
+		wantEnabled bool
+	}{
+		{
+			name:        "missing section",
+			cfg:         nil,
+			branch:      "main",
+			want:        AutoMerge{},
+			wantEnabled: false,
+		},
+		{
+			name:   "defaults",
+			cfg:    cfg,
+			branch: "main",
+			want: AutoMerge{
+				Enabled:            true,
+				Label:              "ready-to-merge",
+				MinimalApprovals:   1,
+				PassingConclusions: []string{"skipped"},
+			},
+			wantEnabled: true,
+		},
+		{
+			name:   "release branch override",
+			cfg:    cfg,
+			branch: "v1.16",
+			want: AutoMerge{
+				Enabled:            true,
+				Label:              "ready-to-merge",
+				MinimalApprovals:   2,
+				PassingConclusions: []string{"skipped"},
+			},
+			wantEnabled: true,
+		},
+		{
+			name:   "disabled for branch",
+			cfg:    cfg,
+			branch: "ft/foo",
+			want: AutoMerge{
+				Enabled:            false,
+				Label:              "ready-to-merge",
+				MinimalApprovals:   1,
+				PassingConclusions: []string{"skipped"},
+			},
+			wantEnabled: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotEnabled := tt.cfg.ForBranch(tt.branch)
+			assert.Equalf(t, tt.want, got, "ForBranch(%v)", tt.branch)
+			assert.Equalf(t, tt.wantEnabled, gotEnabled, "ForBranch(%v)", tt.branch)
+		})
+	}
+}
+
+func TestAutoMerge_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    AutoMerge
+		wantErr string
+	}{
+		{
+			name: "branch patterns",
+			yaml: "enabled: true\nbranches:\n  - branch: v*\n    min-approvals: 2",
+			want: AutoMerge{
+				Enabled:  true,
+				Branches: []AutoMergeBranch{{Branch: "v*", MinimalApprovals: 2}},
+			},
+		},
+		{
+			name:    "malformed branch pattern",
+			yaml:    "enabled: true\nbranches:\n  - branch: v[1-\n    enabled: false",
+			wantErr: `invalid branch pattern "v[1-"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got AutoMerge
+			err := yaml.Unmarshal([]byte(tt.yaml), &got)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// Malformed patterns fail the whole configuration.
+	var cfg PRBlockerConfig
+	err := yaml.Unmarshal([]byte("auto-merge:\n  enabled: true\n  branches:\n    - branch: '['"), &cfg)
+	assert.ErrorContains(t, err, `invalid branch pattern "["`)
+}
+
+func TestClient_AutoMerge(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      []string
+		status      string
+		reviews     map[string]string
+		wantLabels  []string
+		wantTitle   string
+		wantSuccess bool
+		wantMerged  bool
+	}{
+		{
+			name:      "required checks pending",
+			status:    "pending",
+			reviews:   map[string]string{"bob": "APPROVED"},
+			wantTitle: "Waiting for required checks",
+		},
+		{
+			name:        "ready",
+			status:      "success",
+			reviews:     map[string]string{"bob": "APPROVED"},
+			wantLabels:  []string{"ready-to-merge"},
+			wantTitle:   "Ready to merge",
+			wantSuccess: true,
+			wantMerged:  true,
+		},
+		{
+			name:      "changes requested",
+			labels:    []string{"ready-to-merge"},
+			status:    "success",
+			reviews:   map[string]string{"bob": "APPROVED", "carol": "CHANGES_REQUESTED"},
+			wantTitle: "Waiting for reviews",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			srv.SetBranchProtection("cilium", "cilium", "main", "ci/build")
+			pr := srv.AddPR("cilium", "cilium", githubtest.PR{
+				Number:  1,
+				Author:  "alice",
+				Labels:  tt.labels,
+				Commits: []githubtest.Commit{{SHA: "abc", Author: "alice"}},
+			})
+			srv.SetStatus("cilium", "cilium", "abc", "ci/build", tt.status)
+			for user, state := range tt.reviews {
+				srv.AddReview("cilium", "cilium", 1, user, state, "abc")
+			}
+			cfg, _ := (&AutoMerge{
+				Enabled: true,
+				Merge:   &MergeConfig{Mode: MergeModeMerge},
+			}).ForBranch("main")
+
+			err := c.AutoMerge(cfg, "cilium", "cilium", pr.GetBase(), pr.GetHead(), 1, parseGHLabels(pr.Labels), nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantLabels, srv.Labels("cilium", "cilium", 1))
+			cr := srv.CheckRun("cilium", "cilium", "abc", autoMergeStatusCheckName)
+			assert.Equal(t, tt.wantTitle, cr.GetOutput().GetTitle())
+			assert.Equal(t, tt.wantSuccess, cr.GetConclusion() == "success")
+			assert.Equal(t, tt.wantMerged, srv.Merged("cilium", "cilium", 1))
+		})
+	}
+}
+
+func TestClient_getCIStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		required []*gh.RequiredStatusCheck
+		want     CIChecks
+	}{
+		{
+			name:     "own check runs are not CI checks",
+			required: []*gh.RequiredStatusCheck{{Context: "ci/build"}},
+		},
+		{
+			name: "own check runs are never required",
+			required: []*gh.RequiredStatusCheck{
+				{Context: mergeabilityCheckName},
+				{Context: autoMergeStatusCheckName, AppID: new(githubtest.AppID)},
+			},
+		},
+		{
+			name:     "check of another app with the same name",
+			required: []*gh.RequiredStatusCheck{{Context: mergeabilityCheckName, AppID: new(int64(2))}},
+			want:     CIChecks{{Name: mergeabilityCheckName, State: CIStateMissing}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			srv.SetBranchProtectionChecks("cilium", "cilium", "main", tt.required...)
+			pr := srv.AddPR("cilium", "cilium", githubtest.PR{
+				Number:  1,
+				Commits: []githubtest.Commit{{SHA: "abc"}},
+			})
+			srv.SetStatus("cilium", "cilium", "abc", "ci/build", "success")
+			srv.AddCheckRun("cilium", "cilium", "abc", githubtest.AppID, mergeabilityCheckName, "completed", "failure")
+			srv.AddCheckRun("cilium", "cilium", "abc", githubtest.AppID, autoMergeStatusCheckName, "completed", "neutral")
+			// Our own check run named after a required check still counts.
+			srv.AddCheckRun("cilium", "cilium", "abc", githubtest.AppID, "ci/build", "completed", "success")
+
+			got, err := c.getCIStatus("cilium", "cilium", pr.GetBase(), pr.GetHead(), 1, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+)
+
+// autoMergeStatusCheckName is the name of the check run that explains the
+// outcome of the last auto-merge evaluation of a PR.
+const autoMergeStatusCheckName = "Auto-merge status"
+
+// autoMergeStatus is the outcome of an auto-merge evaluation.
+type autoMergeStatus struct {
+	// Label is the label set once the PR is ready to merge.
+	Label string
+	// CIChecks are the required checks that did not pass yet. Reviews are
+	// not evaluated while there are CI checks.
+	CIChecks CIChecks
+	// ReviewsRequested are the users with stale changes requested that were
+	// asked to review the PR again.
+	ReviewsRequested []string
+
+	Approvals             int
+	MinimalApprovals      int
+	IneligibleApprovals   map[string]string
+	StaleApprovals        []string
+	PendingUsers          []string
+	PendingTeams          []string
+	ChangesRequested      []string
+	UnsatisfiedCodeOwners []string
+	UnresolvedThreads     []string
+}
+
+// ready returns true if the PR meets all auto-merge conditions.
+func (s *autoMergeStatus) ready() bool {
+	return len(s.CIChecks) == 0 &&
+		len(s.ReviewsRequested) == 0 &&
+		s.Approvals >= s.MinimalApprovals &&
+		len(s.PendingUsers) == 0 &&
+		len(s.PendingTeams) == 0 &&
+		len(s.ChangesRequested) == 0 &&
+		len(s.UnsatisfiedCodeOwners) == 0 &&
+		len(s.UnresolvedThreads) == 0
+}
+
+func (s *autoMergeStatus) title() string {
+	switch {
+	case s.ready():
+		return "Ready to merge"
+	case s.CIChecks.Broken():
+		return "Required checks not passed"
+	case len(s.CIChecks) != 0:
+		return "Waiting for required checks"
+	default:
+		return "Waiting for reviews"
+	}
+}
+
+// outcome returns the first auto-merge condition that is not met, or "ready".
+func (s *autoMergeStatus) outcome() string {
+	switch {
+	case s.ready():
+		return "ready"
+	case s.CIChecks.Broken():
+		return "checks-failed"
+	case len(s.CIChecks) != 0:
+		return "checks-pending"
+	case len(s.ReviewsRequested) != 0:
+		return "reviews-requested"
+	case len(s.ChangesRequested) != 0:
+		return "changes-requested"
+	case s.Approvals < s.MinimalApprovals:
+		return "missing-approvals"
+	case len(s.PendingUsers) != 0 || len(s.PendingTeams) != 0:
+		return "pending-reviews"
+	case len(s.UnsatisfiedCodeOwners) != 0:
+		return "code-owners"
+	case len(s.UnresolvedThreads) != 0:
+		return "unresolved-threads"
+	default:
+		// Unreachable unless a condition is missing above.
+		return "unknown"
+	}
+}
+
+// summary returns the markdown summary of the check run.
+func (s *autoMergeStatus) summary() string {
+	var b strings.Builder
+	if s.ready() {
+		fmt.Fprintf(&b, "All auto-merge conditions are met, the %q label is set.", s.Label)
+	} else {
+		fmt.Fprintf(&b, "The %q label is not set until all the conditions below are met.", s.Label)
+	}
+
+	writeList := func(name string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		b.WriteString(mergeabilitySectionHeader + name + "\n")
+		for _, item := range items {
+			b.WriteString("\n- " + item)
+		}
+	}
+
+	writeList("Required checks not passed", s.CIChecks.Strings())
+	if len(s.CIChecks) != 0 {
+		b.WriteString("\n\nReviews are evaluated once all required checks pass.")
+		return b.String()
+	}
+
+	writeList("Reviews requested again because of stale changes requested", mentions(s.ReviewsRequested))
+	if len(s.ReviewsRequested) != 0 {
+		return b.String()
+	}
+
+	b.WriteString(mergeabilitySectionHeader + "Approvals\n")
+	fmt.Fprintf(&b, "\n%d of %d required approvals.", s.Approvals, s.MinimalApprovals)
+	var ignored []string
+	for _, user := range slices.Sorted(maps.Keys(s.IneligibleApprovals)) {
+		ignored = append(ignored, fmt.Sprintf("@%s: %s", user, s.IneligibleApprovals[user]))
+	}
+	for _, user := range s.StaleApprovals {
+		ignored = append(ignored, fmt.Sprintf("@%s: stale", user))
+	}
+	writeList("Ignored approvals", ignored)
+	writeList("Pending reviewers", append(mentions(s.PendingUsers), mentions(s.PendingTeams)...))
+	writeList("Changes requested", mentions(s.ChangesRequested))
+	writeList("Code owners without approval", s.UnsatisfiedCodeOwners)
+	writeList("Unresolved review threads", s.UnresolvedThreads)
+	return b.String()
+}
+
+// reasons returns the auto-merge conditions that are not met, one per line.
+func (s *autoMergeStatus) reasons() []string {
+	var r []string
+	for _, cc := range s.CIChecks {
+		r = append(r, "required check "+cc.String())
+	}
+	for _, user := range s.ReviewsRequested {
+		r = append(r, fmt.Sprintf("review requested again from @%s because of stale changes requested", user))
+	}
+	if s.Approvals < s.MinimalApprovals {
+		r = append(r, fmt.Sprintf("%d of %d required approvals", s.Approvals, s.MinimalApprovals))
+	}
+	for _, user := range slices.Sorted(maps.Keys(s.IneligibleApprovals)) {
+		r = append(r, fmt.Sprintf("approval of @%s ignored: %s", user, s.IneligibleApprovals[user]))
+	}
+	for _, user := range s.StaleApprovals {
+		r = append(r, fmt.Sprintf("approval of @%s ignored: stale", user))
+	}
+	for _, reviewer := range append(slices.Clone(s.PendingUsers), s.PendingTeams...) {
+		r = append(r, fmt.Sprintf("pending review from @%s", reviewer))
+	}
+	for _, user := range s.ChangesRequested {
+		r = append(r, fmt.Sprintf("changes requested by @%s", user))
+	}
+	for _, owner := range s.UnsatisfiedCodeOwners {
+		r = append(r, "code owner without approval: "+owner)
+	}
+	for _, thread := range s.UnresolvedThreads {
+		r = append(r, "unresolved review thread: "+thread)
+	}
+	return r
+}
+
+// mentions returns the given users, or teams, prefixed with "@".
+func mentions(logins []string) []string {
+	var m []string
+	for _, login := range logins {
+		m = append(m, "@"+login)
+	}
+	return m
+}
+
+// updateAutoMergeStatus creates, or updates, the "Auto-merge status" check
+// run of the given head SHA with the outcome of an auto-merge evaluation. The
+// check run is "success" if the PR is ready to merge and "neutral" otherwise
+// so that it never blocks the PR by itself.
+func (c *Client) updateAutoMergeStatus(owner, repoName string, prNumber int, headSHA string, s *autoMergeStatus) error {
+	c.metrics.autoMergeEvaluated(s.outcome())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	conclusion := "neutral"
+	if s.ready() {
+		conclusion = "success"
+	}
+	output := &gh.CheckRunOutput{
+		Title:   new(s.title()),
+		Summary: new(s.summary()),
+	}
+
+	lc, _, err := c.GHClient.Checks.ListCheckRunsForRef(ctx, owner, repoName, headSHA, c.ownCheckRunsOptions(autoMergeStatusCheckName))
+	if err != nil && !IsNotFound(err) {
+		return err
+	}
+	if lc != nil && len(lc.CheckRuns) != 0 {
+		cr := lc.CheckRuns[0]
+		if cr.GetConclusion() == conclusion &&
+			cr.GetOutput().GetTitle() == output.GetTitle() &&
+			cr.GetOutput().GetSummary() == output.GetSummary() {
+			return nil
+		}
+		err = c.updateCheckRun(ctx, ruleAutoMerge, owner, repoName, prNumber, cr.GetID(), gh.UpdateCheckRunOptions{
+			Name:        autoMergeStatusCheckName,
+			Status:      new("completed"),
+			Conclusion:  &conclusion,
+			CompletedAt: &gh.Timestamp{Time: time.Now()},
+			Output:      output,
+			Actions:     reevaluateActions,
+		})
+	} else {
+		err = c.createCheckRun(ctx, ruleAutoMerge, owner, repoName, prNumber, gh.CreateCheckRunOptions{
+			Name:        autoMergeStatusCheckName,
+			HeadSHA:     headSHA,
+			Status:      new("completed"),
+			Conclusion:  &conclusion,
+			CompletedAt: &gh.Timestamp{Time: time.Now()},
+			Output:      output,
+			Actions:     reevaluateActions,
+		})
+	}
+	c.log.Info().Fields(map[string]interface{}{
+		"pr-number":  prNumber,
+		"conclusion": conclusion,
+	}).Err(err).Msg("Updating auto-merge status for PR")
+	return err
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_autoMergeStatus_summary(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      autoMergeStatus
+		wantTitle   string
+		want        string
+		wantReasons []string
+	}{
+		{
+			name: "ready",
+			status: autoMergeStatus{
+				Label:            "ready-to-merge",
+				Approvals:        1,
+				MinimalApprovals: 1,
+			},
+			wantTitle: "Ready to merge",
+			want: "All auto-merge conditions are met, the \"ready-to-merge\" label is set." +
+				"\n\n### Approvals\n\n1 of 1 required approvals.",
+		},
+		{
+			name: "required checks",
+			status: autoMergeStatus{
+				Label:    "ready-to-merge",
+				CIChecks: CIChecks{{Name: "build", State: CIStatePending}},
+			},
+			wantTitle: "Waiting for required checks",
+			want: "The \"ready-to-merge\" label is not set until all the conditions below are met." +
+				"\n\n### Required checks not passed\n\n- build: pending" +
+				"\n\nReviews are evaluated once all required checks pass.",
+			wantReasons: []string{"required check build: pending"},
+		},
+		{
+			name: "reviews",
+			status: autoMergeStatus{
+				Label:               "ready-to-merge",
+				Approvals:           1,
+				MinimalApprovals:    2,
+				IneligibleApprovals: map[string]string{"bot[bot]": "bot account"},
+				PendingUsers:        []string{"alice"},
+				PendingTeams:        []string{"cilium/committers"},
+				ChangesRequested:    []string{"bob"},
+			},
+			wantTitle: "Waiting for reviews",
+			want: "The \"ready-to-merge\" label is not set until all the conditions below are met." +
+				"\n\n### Approvals\n\n1 of 2 required approvals." +
+				"\n\n### Ignored approvals\n\n- @bot[bot]: bot account" +
+				"\n\n### Pending reviewers\n\n- @alice\n- @cilium/committers" +
+				"\n\n### Changes requested\n\n- @bob",
+			wantReasons: []string{
+				"1 of 2 required approvals",
+				"approval of @bot[bot] ignored: bot account",
+				"pending review from @alice",
+				"pending review from @cilium/committers",
+				"changes requested by @bob",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantTitle, tt.status.title())
+			assert.Equal(t, tt.want, tt.status.summary())
+			assert.Equal(t, tt.wantReasons, tt.status.reasons())
+		})
+	}
+}
+
+func Test_autoMergeStatus_outcome(t *testing.T) {
+	tests := []struct {
+		status autoMergeStatus
+		want   string
+	}{
+		{status: autoMergeStatus{}, want: "ready"},
+		{status: autoMergeStatus{CIChecks: CIChecks{{Name: "build", State: CIStateFailed}}}, want: "checks-failed"},
+		{status: autoMergeStatus{CIChecks: CIChecks{{Name: "build", State: CIStatePending}}}, want: "checks-pending"},
+		{status: autoMergeStatus{ReviewsRequested: []string{"bob"}}, want: "reviews-requested"},
+		{status: autoMergeStatus{ChangesRequested: []string{"bob"}}, want: "changes-requested"},
+		{status: autoMergeStatus{MinimalApprovals: 1}, want: "missing-approvals"},
+		{status: autoMergeStatus{PendingTeams: []string{"cilium/committers"}}, want: "pending-reviews"},
+		{status: autoMergeStatus{UnsatisfiedCodeOwners: []string{"@cilium/committers"}}, want: "code-owners"},
+		{status: autoMergeStatus{UnresolvedThreads: []string{"thread"}}, want: "unresolved-threads"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.status.outcome())
+		})
+	}
+}
+
+func TestClient_updateAutoMergeStatus(t *testing.T) {
+	srv, c := newFakeClient(t)
+	srv.AddPR("cilium", "cilium", githubtest.PR{Commits: []githubtest.Commit{{SHA: "c1"}}})
+	// A check run of another app with the same name is never updated.
+	other := srv.AddCheckRun("cilium", "cilium", "c1", 2, autoMergeStatusCheckName, "completed", "failure")
+
+	err := c.updateAutoMergeStatus("cilium", "cilium", 1, "c1", &autoMergeStatus{Label: "ready-to-merge", MinimalApprovals: 1})
+	assert.NoError(t, err)
+	runs := srv.CheckRuns("cilium", "cilium", "c1")
+	if assert.Len(t, runs, 2) {
+		assert.Equal(t, other, runs[0].GetID())
+		assert.Equal(t, "failure", runs[0].GetConclusion())
+		assert.Equal(t, githubtest.AppID, runs[1].GetApp().GetID())
+		assert.Equal(t, "neutral", runs[1].GetConclusion())
+	}
+
+	err = c.updateAutoMergeStatus("cilium", "cilium", 1, "c1", &autoMergeStatus{Label: "ready-to-merge", Approvals: 1, MinimalApprovals: 1})
+	assert.NoError(t, err)
+	runs = srv.CheckRuns("cilium", "cilium", "c1")
+	if assert.Len(t, runs, 2) {
+		assert.Equal(t, "failure", runs[0].GetConclusion())
+		assert.Equal(t, "success", runs[1].GetConclusion())
+	}
+
+	// The check run is not updated again if the outcome did not change.
+	requests := len(srv.Requests())
+	err = c.updateAutoMergeStatus("cilium", "cilium", 1, "c1", &autoMergeStatus{Label: "ready-to-merge", Approvals: 1, MinimalApprovals: 1})
+	assert.NoError(t, err)
+	assert.Len(t, srv.Requests(), requests+1)
+	assert.NotContains(t, srv.Requests()[requests], "PATCH")
+}
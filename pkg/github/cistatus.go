@@ -0,0 +1,155 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// CIState is the state of a required check in the head SHA of a PR.
+type CIState string
+
+const (
+	CIStatePassed    CIState = "passed"
+	CIStatePending   CIState = "pending"
+	CIStateFailed    CIState = "failed"
+	CIStateCancelled CIState = "cancelled"
+	// CIStateMissing is a required check that was never reported.
+	CIStateMissing CIState = "missing"
+	// CIStateStale is a required check that was only reported for a previous
+	// SHA of the PR, or that GitHub marked as stale.
+	CIStateStale CIState = "stale"
+)
+
+// defaultPassingConclusions are the check run conclusions, besides "success",
+// that satisfy a required check if AutoMerge.PassingConclusions is not set.
+var defaultPassingConclusions = []string{"skipped"}
+
+// ciStateSeverity orders the states from the least to the most severe. If a
+// required check is reported multiple times, the most severe state wins.
+var ciStateSeverity = map[CIState]int{
+	CIStatePassed:    0,
+	CIStateMissing:   1,
+	CIStateStale:     2,
+	CIStatePending:   3,
+	CIStateCancelled: 4,
+	CIStateFailed:    5,
+}
+
+// Waiting returns true if the check may still pass without any intervention.
+func (s CIState) Waiting() bool {
+	return s == CIStatePending
+}
+
+// CICheck is a required check that did not pass.
+type CICheck struct {
+	Name  string
+	State CIState
+	// URL is the details URL of the check, if any.
+	URL string
+}
+
+func (cc CICheck) String() string {
+	if cc.URL != "" {
+		return fmt.Sprintf("[%s](%s): %s", cc.Name, cc.URL, cc.State)
+	}
+	return fmt.Sprintf("%s: %s", cc.Name, cc.State)
+}
+
+// CIChecks are the required checks that did not pass, sorted by name.
+type CIChecks []CICheck
+
+// Broken returns true if any of the checks failed, was cancelled or is
+// missing, i.e., if the PR will not become mergeable by just waiting.
+func (cc CIChecks) Broken() bool {
+	return slices.ContainsFunc(cc, func(c CICheck) bool {
+		return !c.State.Waiting()
+	})
+}
+
+// Strings returns the checks formatted as markdown.
+func (cc CIChecks) Strings() []string {
+	var s []string
+	for _, c := range cc {
+		s = append(s, c.String())
+	}
+	return s
+}
+
+// ciResults collects the states reported for each required check.
+type ciResults map[string]CICheck
+
+// add records the state reported for a check, keeping the most severe one.
+func (r ciResults) add(name string, state CIState, url string) {
+	if prev, ok := r[name]; ok && ciStateSeverity[prev.State] >= ciStateSeverity[state] {
+		return
+	}
+	r[name] = CICheck{Name: name, State: state, URL: url}
+}
+
+// checks returns the required checks, from 'required', that did not pass.
+func (r ciResults) checks(required requiredChecks) CIChecks {
+	var cc CIChecks
+	for name := range required {
+		res, ok := r[name]
+		switch {
+		case !ok:
+			cc = append(cc, CICheck{Name: name, State: CIStateMissing})
+		case res.State != CIStatePassed:
+			cc = append(cc, res)
+		}
+	}
+	sort.Slice(cc, func(i, j int) bool { return cc[i].Name < cc[j].Name })
+	return cc
+}
+
+// commitStatusState maps the state of a commit status to a CIState.
+func commitStatusState(state string) CIState {
+	switch state {
+	case "success":
+		return CIStatePassed
+	case "pending":
+		return CIStatePending
+	default:
+		// "failure" and "error"
+		return CIStateFailed
+	}
+}
+
+// checkRunState maps the status and conclusion of a check run to a CIState.
+// Besides "success", only the given conclusions satisfy a required check.
+func checkRunState(status, conclusion string, passingConclusions []string) CIState {
+	if status != "completed" {
+		return CIStatePending
+	}
+	switch conclusion {
+	case "success":
+		return CIStatePassed
+	case "cancelled":
+		return CIStateCancelled
+	case "stale":
+		return CIStateStale
+	case "action_required":
+		return CIStatePending
+	}
+	if slices.Contains(passingConclusions, conclusion) {
+		return CIStatePassed
+	}
+	// "failure", "timed_out", "startup_failure" and non-passing "neutral"
+	// or "skipped".
+	return CIStateFailed
+}
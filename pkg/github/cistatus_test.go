@@ -0,0 +1,75 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_checkRunState(t *testing.T) {
+	tests := []struct {
+		status             string
+		conclusion         string
+		passingConclusions []string
+		want               CIState
+	}{
+		{status: "in_progress", want: CIStatePending},
+		{status: "queued", want: CIStatePending},
+		{status: "completed", conclusion: "success", want: CIStatePassed},
+		{status: "completed", conclusion: "failure", want: CIStateFailed},
+		{status: "completed", conclusion: "timed_out", want: CIStateFailed},
+		{status: "completed", conclusion: "cancelled", want: CIStateCancelled},
+		{status: "completed", conclusion: "stale", want: CIStateStale},
+		{status: "completed", conclusion: "skipped", passingConclusions: defaultPassingConclusions, want: CIStatePassed},
+		{status: "completed", conclusion: "neutral", passingConclusions: defaultPassingConclusions, want: CIStateFailed},
+		{status: "completed", conclusion: "neutral", passingConclusions: []string{"neutral"}, want: CIStatePassed},
+		{status: "completed", conclusion: "skipped", passingConclusions: []string{}, want: CIStateFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status+"/"+tt.conclusion, func(t *testing.T) {
+			assert.Equal(t, tt.want, checkRunState(tt.status, tt.conclusion, tt.passingConclusions))
+		})
+	}
+}
+
+func Test_ciResults_checks(t *testing.T) {
+	results := ciResults{}
+	results.add("build", CIStatePassed, "")
+	results.add("test", CIStatePassed, "")
+	results.add("test", CIStateFailed, "https://ci/test/2")
+	results.add("test", CIStatePending, "https://ci/test/3")
+	results.add("lint", CIStatePending, "")
+	results.add("optional", CIStateFailed, "")
+
+	checks := results.checks(requiredChecks{
+		"build":  anyApp,
+		"test":   anyApp,
+		"lint":   anyApp,
+		"e2e":    anyApp,
+		"docs":   anyApp,
+		"ignore": 42,
+	})
+	assert.Equal(t, CIChecks{
+		{Name: "docs", State: CIStateMissing},
+		{Name: "e2e", State: CIStateMissing},
+		{Name: "ignore", State: CIStateMissing},
+		{Name: "lint", State: CIStatePending},
+		{Name: "test", State: CIStateFailed, URL: "https://ci/test/2"},
+	}, checks)
+	assert.True(t, checks.Broken())
+	assert.False(t, checks[3:4].Broken())
+}
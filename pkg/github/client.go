@@ -17,34 +17,63 @@ package github
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/cilium/github-actions/pkg/jenkins"
 	gh "github.com/google/go-github/v84/github"
+	"github.com/rcrowley/go-metrics"
 	"github.com/rs/zerolog"
+	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
 )
 
 type Client struct {
 	GHClient   *gh.Client
+	GHV4Client *githubv4.Client
 	log        *zerolog.Logger
 	orgName    string
 	repoName   string
 	clientMode bool
+
+	teamMembers *TeamMembersCache
+	ciDebouncer *CIDebouncer
+	metrics     *Metrics
+
+	plannedMu sync.Mutex
+	// shadow makes the client record the mutations in 'planned' instead of
+	// performing them.
+	shadow  bool
+	planned []PlannedAction
+
+	// auditLog, if set, records all mutations along with deliveryID, the
+	// webhook delivery that triggered them.
+	auditLog   *AuditLog
+	deliveryID string
+
+	// scheduler, if set, runs the evaluations of PRs triggered by events
+	// that are not tied to a single PR.
+	scheduler Scheduler
+
+	// appID is the ID of the GitHub App the client authenticates as, if any.
+	// The check runs the client looks up to update are filtered by it.
+	appID int64
 }
 
 func NewClient(ghToken string, orgName, repo string, logger *zerolog.Logger) *Client {
-	return &Client{
-		GHClient: gh.NewClient(
-			oauth2.NewClient(
-				context.Background(),
-				oauth2.StaticTokenSource(
-					&oauth2.Token{
-						AccessToken: ghToken,
-					},
-				),
-			),
+	httpClient := oauth2.NewClient(
+		context.Background(),
+		oauth2.StaticTokenSource(
+			&oauth2.Token{
+				AccessToken: ghToken,
+			},
 		),
+	)
+	// Client mode has a single token, there are no installations.
+	httpClient.Transport = NewRateLimiter(metrics.DefaultRegistry).Middleware("token")(httpClient.Transport)
+	return &Client{
+		GHClient:   SkipRateLimitCheck(gh.NewClient(httpClient)),
+		GHV4Client: githubv4.NewClient(httpClient),
 		orgName:    orgName,
 		repoName:   repo,
 		clientMode: true,
@@ -52,13 +81,30 @@ func NewClient(ghToken string, orgName, repo string, logger *zerolog.Logger) *Cl
 	}
 }
 
-func NewClientFromGHClient(ghClient *gh.Client, orgName, repo string, logger *zerolog.Logger) *Client {
+func NewClientFromGHClient(ghClient *gh.Client, ghV4Client *githubv4.Client, orgName, repo string, logger *zerolog.Logger) *Client {
 	return &Client{
-		GHClient: ghClient,
-		log:      logger,
-		orgName:  orgName,
-		repoName: repo,
+		GHClient:   ghClient,
+		GHV4Client: ghV4Client,
+		log:        logger,
+		orgName:    orgName,
+		repoName:   repo,
+	}
+}
+
+// SetAppID sets the ID of the GitHub App the client authenticates as so that
+// it only updates the check runs created by that app.
+func (c *Client) SetAppID(id int64) {
+	c.appID = id
+}
+
+// ownCheckRunsOptions returns the options to list the check runs with the
+// given name that the client may update.
+func (c *Client) ownCheckRunsOptions(name string) *gh.ListCheckRunsOptions {
+	opts := &gh.ListCheckRunsOptions{CheckName: new(name)}
+	if c.appID != 0 {
+		opts.AppID = new(c.appID)
 	}
+	return opts
 }
 
 func (c *Client) GetConfigFile(owner, repoName, file, sha string) ([]byte, error) {
@@ -0,0 +1,238 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	gh "github.com/google/go-github/v84/github"
+)
+
+// codeOwnersPaths are the locations where GitHub looks for the CODEOWNERS
+// file, in order of precedence.
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type codeOwnersRule struct {
+	pattern string
+	re      *regexp.Regexp
+	// owners are the users ("user") and teams ("org/team") that own the
+	// files matching the pattern.
+	owners []string
+}
+
+// codeOwners is the list of rules of a CODEOWNERS file. As in GitHub, the last
+// rule matching a file takes precedence.
+type codeOwners []codeOwnersRule
+
+// parseCodeOwners parses the contents of a CODEOWNERS file. Owners specified
+// by email address are ignored since they can't be mapped to a GitHub login.
+func parseCodeOwners(content []byte) (codeOwners, error) {
+	var co codeOwners
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		re, err := codeOwnersPatternRegexp(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CODEOWNERS pattern %q: %w", fields[0], err)
+		}
+		rule := codeOwnersRule{
+			pattern: fields[0],
+			re:      re,
+		}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "@") {
+				rule.owners = append(rule.owners, strings.ToLower(strings.TrimPrefix(owner, "@")))
+			}
+		}
+		co = append(co, rule)
+	}
+	return co, scanner.Err()
+}
+
+// codeOwnersPatternRegexp converts a CODEOWNERS pattern, which follows most
+// of the gitignore rules, into a regular expression matched against file
+// paths relative to the root of the repository.
+func codeOwnersPatternRegexp(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	// A separator at the beginning or middle of the pattern makes it relative
+	// to the root of the repository.
+	if strings.Contains(pattern, "/") {
+		anchored = true
+	}
+
+	var re strings.Builder
+	if anchored {
+		re.WriteString("^")
+	} else {
+		re.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; ch {
+		case '*':
+			switch {
+			case strings.HasPrefix(pattern[i:], "**/"):
+				re.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(pattern[i:], "**"):
+				re.WriteString(".*")
+				i++
+			default:
+				re.WriteString("[^/]*")
+			}
+		case '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	switch {
+	case dirOnly:
+		re.WriteString("/")
+	case strings.HasSuffix(pattern, "/*"):
+		// "docs/*" only matches the files directly under "docs".
+		re.WriteString("$")
+	default:
+		// Patterns also match all files under a matching directory.
+		re.WriteString("(?:$|/)")
+	}
+	return regexp.Compile(re.String())
+}
+
+// Owners returns the owners of the given file, or nil if the file does not
+// have any owners.
+func (co codeOwners) Owners(file string) []string {
+	for i := len(co) - 1; i >= 0; i-- {
+		if co[i].re.MatchString(file) {
+			return co[i].owners
+		}
+	}
+	return nil
+}
+
+// getCodeOwners returns the CODEOWNERS of the repository at the given SHA or
+// nil if the repository does not have a CODEOWNERS file.
+func (c *Client) getCodeOwners(owner, repoName, sha string) (codeOwners, error) {
+	for _, path := range codeOwnersPaths {
+		content, err := c.GetConfigFile(owner, repoName, path, sha)
+		switch {
+		case IsNotFound(err) || IsNotFound(errors.Unwrap(err)):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		return parseCodeOwners(content)
+	}
+	return nil, nil
+}
+
+// getPRFiles returns the files changed by the given PR.
+func (c *Client) getPRFiles(ctx context.Context, owner, repoName string, prNumber int) ([]string, error) {
+	var files []string
+	nextPage := 0
+	for {
+		commitFiles, resp, err := c.GHClient.PullRequests.ListFiles(ctx, owner, repoName, prNumber, &gh.ListOptions{
+			Page:    nextPage,
+			PerPage: 100,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range commitFiles {
+			files = append(files, f.GetFilename())
+		}
+		nextPage = resp.NextPage
+		if nextPage == 0 {
+			break
+		}
+	}
+	return files, nil
+}
+
+// unsatisfiedCodeOwners returns the groups of code owners, of the files
+// changed by the PR, that do not have an approval from any of their users or
+// team members. Each group is formatted as in the CODEOWNERS file, i.e., the
+// owners of a file separated by spaces. Approvers must be lower case logins.
+func (c *Client) unsatisfiedCodeOwners(ctx context.Context, owner, repoName, baseSHA string, prNumber int, approvers map[string]struct{}) ([]string, error) {
+	co, err := c.getCodeOwners(owner, repoName, baseSHA)
+	if err != nil {
+		return nil, err
+	}
+	if len(co) == 0 {
+		return nil, nil
+	}
+
+	files, err := c.getPRFiles(ctx, owner, repoName, prNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string][]string{}
+	for _, file := range files {
+		owners := co.Owners(file)
+		if len(owners) == 0 {
+			continue
+		}
+		groups["@"+strings.Join(owners, " @")] = owners
+	}
+
+	var unsatisfied []string
+	for group, owners := range groups {
+		ok, err := c.approvedByOwners(ctx, owners, approvers)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			unsatisfied = append(unsatisfied, group)
+		}
+	}
+	sort.Strings(unsatisfied)
+	return unsatisfied, nil
+}
+
+// approvedByOwners returns true if any of the given owners, or any member of
+// the given teams, is part of the approvers.
+func (c *Client) approvedByOwners(ctx context.Context, owners []string, approvers map[string]struct{}) (bool, error) {
+	for _, owner := range owners {
+		org, slug, isTeam := strings.Cut(owner, "/")
+		if !isTeam {
+			if _, ok := approvers[owner]; ok {
+				return true, nil
+			}
+			continue
+		}
+		ok, err := c.approvedByTeam(ctx, org, slug, approvers)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
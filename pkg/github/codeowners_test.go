@@ -0,0 +1,130 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOwners_Owners(t *testing.T) {
+	co, err := parseCodeOwners([]byte(`
+# Default owners
+*                       @cilium/committers
+*.md                    @cilium/docs-structure
+/bpf/                   @cilium/sig-datapath # inline comment
+docs/*                  @cilium/docs
+/pkg/**/policy          @cilium/sig-policy @joe
+Documentation/cmdref    someone@example.com
+/api/v1/flow.proto
+`))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{file: "Makefile", want: []string{"cilium/committers"}},
+		{file: "pkg/README.md", want: []string{"cilium/docs-structure"}},
+		{file: "bpf/lib/nat.h", want: []string{"cilium/sig-datapath"}},
+		{file: "test/bpf/foo.c", want: []string{"cilium/committers"}},
+		{file: "docs/index.rst", want: []string{"cilium/docs"}},
+		{file: "docs/sub/index.rst", want: []string{"cilium/committers"}},
+		{file: "pkg/policy/rule.go", want: []string{"cilium/sig-policy", "joe"}},
+		{file: "pkg/k8s/apis/policy/types.go", want: []string{"cilium/sig-policy", "joe"}},
+		{file: "Documentation/cmdref/cilium.md", want: nil},
+		{file: "api/v1/flow.proto", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			assert.Equalf(t, tt.want, co.Owners(tt.file), "Owners(%v)", tt.file)
+		})
+	}
+}
+
+func TestClient_unsatisfiedCodeOwners(t *testing.T) {
+	const codeOwners = `
+*                 @cilium/committers
+/bpf/             @cilium/sig-datapath @joe
+/Documentation/   @cilium/docs
+/api/v1/flow.proto
+`
+	tests := []struct {
+		name       string
+		codeOwners string
+		files      []string
+		approvers  []string
+		want       []string
+	}{
+		{
+			name:  "no CODEOWNERS file",
+			files: []string{"Makefile"},
+			want:  nil,
+		},
+		{
+			name:       "no approvals",
+			codeOwners: codeOwners,
+			files:      []string{"Makefile", "bpf/lib/nat.h", "bpf/lib/lb.h"},
+			want:       []string{"@cilium/committers", "@cilium/sig-datapath @joe"},
+		},
+		{
+			name:       "approved by a user owner",
+			codeOwners: codeOwners,
+			files:      []string{"bpf/lib/nat.h"},
+			approvers:  []string{"joe"},
+			want:       nil,
+		},
+		{
+			name:       "approved by team members",
+			codeOwners: codeOwners,
+			files:      []string{"Makefile", "bpf/lib/nat.h", "Documentation/index.rst"},
+			approvers:  []string{"alice", "carol"},
+			want:       []string{"@cilium/docs"},
+		},
+		{
+			name:       "files without owners",
+			codeOwners: codeOwners,
+			files:      []string{"api/v1/flow.proto"},
+			want:       nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			if tt.codeOwners != "" {
+				srv.SetFile("cilium", "cilium", "CODEOWNERS", tt.codeOwners)
+			}
+			srv.SetTeamMembers("cilium", "committers", "alice", "bob")
+			srv.SetTeamMembers("cilium", "sig-datapath", "carol")
+			srv.SetTeamMembers("cilium", "docs", "dave")
+			srv.AddPR("cilium", "cilium", githubtest.PR{
+				BaseSHA: "base",
+				Commits: []githubtest.Commit{{SHA: "head"}},
+				Files:   tt.files,
+			})
+			approvers := map[string]struct{}{}
+			for _, a := range tt.approvers {
+				approvers[a] = struct{}{}
+			}
+
+			got, err := c.unsatisfiedCodeOwners(context.Background(), "cilium", "cilium", "base", 1, approvers)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -16,6 +16,7 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 
@@ -34,34 +35,46 @@ var (
 	regexNewFlake = regexp.MustCompile(`^` + string(MLHCommandNewFlake))
 )
 
+// errIssuePlanned is returned by CreateIssue in shadow mode, where the issue
+// is only planned and thus has no number to refer to.
+var errIssuePlanned = errors.New("issue creation planned in shadow mode")
+
 // CommentAndOpenIssue creates a comment and (re-)opens a GH issue in case it is
 // closed.
 func (c *Client) CommentAndOpenIssue(ctx context.Context, owner, repo string, issueNumber int, body string) error {
-	_, _, err := c.GHClient.Issues.CreateComment(ctx, owner, repo, issueNumber, &gh.IssueComment{
-		Body: &body,
-	})
+	err := c.createComment(ctx, ruleFlakeTracker, owner, repo, issueNumber, body)
 	if err != nil {
 		return err
 	}
 
-	_, _, err = c.GHClient.Issues.Edit(ctx, owner, repo, issueNumber, &gh.IssueRequest{
-		State: func() *string { ; return new("open") }(),
+	return c.mutate(ruleFlakeTracker, "edit-issue", owner, repo, issueNumber, map[string]interface{}{"state": "open"}, func() error {
+		_, _, err := c.GHClient.Issues.Edit(ctx, owner, repo, issueNumber, &gh.IssueRequest{
+			State: func() *string { ; return new("open") }(),
+		})
+		return err
 	})
-
-	return err
 }
 
 // CreateIssue creates a new GH issue. Returns the issue number created.
 func (c *Client) CreateIssue(ctx context.Context, owner, repo string, title, body string, labels []string) (int, error) {
-	ghIssue, _, err := c.GHClient.Issues.Create(ctx, owner, repo, &gh.IssueRequest{
-		Title:  &title,
-		Body:   &body,
-		Labels: &labels,
+	var ghIssue *gh.Issue
+	args := map[string]interface{}{"title": title, "labels": labels}
+	err := c.mutate(ruleFlakeTracker, "create-issue", owner, repo, 0, args, func() error {
+		var err error
+		ghIssue, _, err = c.GHClient.Issues.Create(ctx, owner, repo, &gh.IssueRequest{
+			Title:  &title,
+			Body:   &body,
+			Labels: &labels,
+		})
+		return err
 	})
 	if err != nil {
 		return 0, err
 	}
-	return ghIssue.GetNumber(), err
+	if ghIssue == nil {
+		return 0, errIssuePlanned
+	}
+	return ghIssue.GetNumber(), nil
 }
 
 func IsMLHCommand(s string) MLHCommand {
@@ -122,11 +135,18 @@ func (c *Client) HandleIssueCommentEvent(ctx context.Context, cfg *FlakeConfig,
 
 			// Create a GH issue
 			issueNumber, err := c.CreateIssue(ctx, c.orgName, c.repoName, title, body, cfg.IssueTracker.IssueLabels)
+			if errors.Is(err, errIssuePlanned) {
+				continue
+			}
 			if err != nil {
 				return fmt.Errorf("unable to create GH issue: %w", err)
 			}
 			issueNumbers = append(issueNumbers, issueNumber)
 		}
+		if len(issueNumbers) == 0 {
+			// All issues were only planned, there is nothing to refer to.
+			return nil
+		}
 
 		comment, err = jenkins.PRCommentNewGHIssues(issueNumbers)
 		if err != nil {
@@ -0,0 +1,69 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_flakeIssues(t *testing.T) {
+	srv, c := newFakeClient(t)
+	ctx := context.Background()
+	closed := srv.AddIssue("cilium", "cilium", githubtest.Issue{
+		Title:  "CI: K8sDatapathConfig fails",
+		Author: githubtest.AppLogin,
+		Labels: []string{"ci/flake"},
+		Closed: true,
+	})
+
+	// A flake hit again re-opens its issue.
+	assert.NoError(t, c.CommentAndOpenIssue(ctx, "cilium", "cilium", closed, "PR #1 hit this flake"))
+	assert.Equal(t, []string{"PR #1 hit this flake"}, srv.Comments("cilium", "cilium", closed))
+
+	created, err := c.CreateIssue(ctx, "cilium", "cilium", "CI: K8sUpdates fails", "PR #2 hit this flake", []string{"ci/flake"})
+	assert.NoError(t, err)
+
+	issues := srv.Issues("cilium", "cilium")
+	if !assert.Len(t, issues, 2) {
+		return
+	}
+	assert.Equal(t, "open", issues[0].GetState())
+	assert.Equal(t, created, issues[1].GetNumber())
+	assert.Equal(t, "CI: K8sUpdates fails", issues[1].GetTitle())
+	assert.Equal(t, []string{"ci/flake"}, srv.Labels("cilium", "cilium", created))
+
+	flakes, err := c.GetFlakeIssues(ctx, "cilium", "cilium", githubtest.AppLogin, []string{"ci/flake"})
+	assert.NoError(t, err)
+	assert.Len(t, flakes, 2)
+}
+
+func TestClient_CreateIssue_shadowMode(t *testing.T) {
+	srv, c := newFakeClient(t)
+	c.SetShadowMode(true)
+
+	// The issue is only planned, so it has no number for the flake tracker
+	// to refer to.
+	number, err := c.CreateIssue(context.Background(), "cilium", "cilium", "CI: K8sUpdates fails", "PR #2 hit this flake", []string{"ci/flake"})
+	assert.ErrorIs(t, err, errIssuePlanned)
+	assert.Zero(t, number)
+	assert.Empty(t, srv.Issues("cilium", "cilium"))
+	if assert.Len(t, c.PlannedActions(), 1) {
+		assert.Equal(t, "create-issue", c.PlannedActions()[0].Action)
+	}
+}
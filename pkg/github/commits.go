@@ -93,6 +93,13 @@ func (c *Client) commitMatches(owner, repoName string, prNumber int, re *regexp.
 // CommitContains checks if all commits of the given PR Number contains the
 // each msg provided for each MsgInCommit.
 func (c *Client) CommitContains(msgsInCommit []MsgInCommit, owner, repoName string, prNumber int) error {
+	return c.commitContains(msgsInCommit, owner, repoName, prNumber, nil)
+}
+
+// commitContains is like CommitContains but, if 'prLabels' is not nil, it only
+// comments and sets the labels of a MsgInCommit if its labels are not already
+// set in the PR.
+func (c *Client) commitContains(msgsInCommit []MsgInCommit, owner, repoName string, prNumber int, prLabels PRLabels) error {
 	var cancels []context.CancelFunc
 	defer func() {
 		for _, cancel := range cancels {
@@ -112,13 +119,18 @@ func (c *Client) CommitContains(msgsInCommit []MsgInCommit, owner, repoName stri
 			for _, lbl := range msgRequired.SetLabels {
 				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 				cancels = append(cancels, cancel)
-				_, err := c.GHClient.Issues.RemoveLabelForIssue(ctx, owner, repoName, prNumber, lbl)
+				err := c.removeLabel(ctx, ruleRequireMsgsInCommit, owner, repoName, prNumber, lbl)
 				if err != nil && !IsNotFound(err) {
 					return err
 				}
 			}
 			continue
 		}
+		c.metrics.commitViolations(len(commits))
+		if prLabels != nil && (len(msgRequired.SetLabels) == 0 || subslice(msgRequired.SetLabels, prLabels)) {
+			// Already reported.
+			continue
+		}
 		var comment string
 		if len(commits) == 1 {
 			comment = fmt.Sprintf("Commit %%s does not match %q.", re)
@@ -131,15 +143,13 @@ func (c *Client) CommitContains(msgsInCommit []MsgInCommit, owner, repoName stri
 		comment = fmt.Sprintf(comment, strings.Join(commits, ", "))
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		cancels = append(cancels, cancel)
-		_, _, err = c.GHClient.Issues.CreateComment(ctx, owner, repoName, prNumber, &gh.IssueComment{
-			Body: &comment,
-		})
+		err = c.createComment(ctx, ruleRequireMsgsInCommit, owner, repoName, prNumber, comment)
 		if err != nil {
 			return err
 		}
 		ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
 		cancels = append(cancels, cancel)
-		_, _, err = c.GHClient.Issues.AddLabelsToIssue(ctx, owner, repoName, prNumber, msgRequired.SetLabels)
+		err = c.addLabels(ctx, ruleRequireMsgsInCommit, owner, repoName, prNumber, msgRequired.SetLabels)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,73 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_CommitContains(t *testing.T) {
+	msgs := []MsgInCommit{
+		{
+			Msg:       "Signed-off-by",
+			Helper:    "https://docs.cilium.io/en/stable/contributing/development/contributing_guide/#developer-s-certificate-of-origin",
+			SetLabels: []string{"dont-merge/needs-sign-off"},
+		},
+	}
+	tests := []struct {
+		name         string
+		labels       []string
+		commits      []githubtest.Commit
+		wantLabels   []string
+		wantComments []string
+	}{
+		{
+			name: "missing sign-off",
+			commits: []githubtest.Commit{
+				{SHA: "abc", Message: "foo\n\nSigned-off-by: Alice <alice@example.com>"},
+				{SHA: "def", Message: "bar"},
+			},
+			wantLabels: []string{"dont-merge/needs-sign-off"},
+			wantComments: []string{
+				"Commit def does not match \"Signed-off-by\".\n\n" +
+					"Please follow instructions provided in " + msgs[0].Helper,
+			},
+		},
+		{
+			name:   "signed-off",
+			labels: []string{"dont-merge/needs-sign-off"},
+			commits: []githubtest.Commit{
+				{SHA: "abc", Message: "foo\n\nSigned-off-by: Alice <alice@example.com>"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			srv.AddPR("cilium", "cilium", githubtest.PR{
+				Number:  1,
+				Labels:  tt.labels,
+				Commits: tt.commits,
+			})
+
+			assert.NoError(t, c.CommitContains(msgs, "cilium", "cilium", 1))
+			assert.Equal(t, tt.wantLabels, srv.Labels("cilium", "cilium", 1))
+			assert.Equal(t, tt.wantComments, srv.Comments("cilium", "cilium", 1))
+		})
+	}
+}
@@ -19,14 +19,24 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"gopkg.in/yaml.v2"
 )
 
 type PRBlockerConfig struct {
-	RequireMsgsInCommit []MsgInCommit `yaml:"require-msgs-in-commit,omitempty"`
-	AutoLabel           []string      `yaml:"auto-label,omitempty"`
-	BlockPRWith         BlockPRWith   `yaml:"block-pr-with,omitempty"`
-	AutoMerge           AutoMerge     `yaml:"auto-merge,omitempty"`
-	FlakeTracker        *FlakeConfig  `yaml:"flake-tracker,omitempty"`
+	// Mode, if set to "shadow", makes the bot log the labels, comments,
+	// check runs and other changes it would make in the repository instead
+	// of making them.
+	Mode string `yaml:"mode,omitempty"`
+	// ProjectColumn, if set, adds all opened and reopened PRs to the given
+	// project column.
+	ProjectColumn                `yaml:",inline"`
+	MoveToProjectsForLabelsXORed MoveToProjectsForLabelsXORed `yaml:"move-to-projects-for-labels-xored,omitempty"`
+	RequireMsgsInCommit          []MsgInCommit                `yaml:"require-msgs-in-commit,omitempty"`
+	AutoLabel                    []string                     `yaml:"auto-label,omitempty"`
+	BlockPRWith                  BlockPRWith                  `yaml:"block-pr-with,omitempty"`
+	AutoMerge                    *AutoMerge                   `yaml:"auto-merge,omitempty"`
+	FlakeTracker                 *FlakeConfig                 `yaml:"flake-tracker,omitempty"`
 }
 
 func GetActionsCfg(ghClient *Client, owner, repoName, ghSha string) (string, []byte, error) {
@@ -44,3 +54,26 @@ func GetActionsCfg(ghClient *Client, owner, repoName, ghSha string) (string, []b
 	}
 	return "", nil, nil
 }
+
+// LoadConfig returns the path and the config of the repository at the given
+// SHA, or nil if the repository does not have a config. The config of a PR
+// must be loaded at the base SHA of the PR, never at its head, as the PR could
+// otherwise change the rules it is evaluated with.
+func LoadConfig(ghClient *Client, owner, repoName, ghSha string) (string, *PRBlockerConfig, error) {
+	actionCfgPath, cfgFile, err := GetActionsCfg(ghClient, owner, repoName, ghSha)
+	if err != nil {
+		ghClient.Metrics().ConfigLoadFailed(owner, repoName)
+		return "", nil, err
+	}
+	if actionCfgPath == "" {
+		return "", nil, nil
+	}
+
+	var c PRBlockerConfig
+	err = yaml.Unmarshal(cfgFile, &c)
+	if err != nil {
+		ghClient.Metrics().ConfigLoadFailed(owner, repoName)
+		return "", nil, fmt.Errorf("unable to unmarshal config %q file: %s", actionCfgPath, err)
+	}
+	return actionCfgPath, &c, nil
+}
@@ -26,6 +26,40 @@ import (
 func TestConfigParser(t *testing.T) {
 
 	expect := PRBlockerConfig{
+		ProjectColumn: ProjectColumn{
+			Project: "https://github.com/orgs/cilium/projects/80",
+			Column:  "In progress",
+		},
+		MoveToProjectsForLabelsXORed: MoveToProjectsForLabelsXORed{
+			"v1.6": {
+				"needs-backport/1.6": {
+					Project: "https://github.com/orgs/cilium/projects/91",
+					Column:  "Needs backport from master",
+				},
+				"backport-pending/1.6": {
+					Project: "https://github.com/orgs/cilium/projects/91",
+					Column:  "Backport pending to v1.6",
+				},
+				"backport-done/1.6": {
+					Project: "https://github.com/orgs/cilium/projects/91",
+					Column:  "Backport done to v1.6",
+				},
+			},
+			"v1.5": {
+				"needs-backport/1.5": {
+					Project: "https://github.com/orgs/cilium/projects/92",
+					Column:  "Needs backport from master",
+				},
+				"backport-pending/1.5": {
+					Project: "https://github.com/orgs/cilium/projects/92",
+					Column:  "Backport pending to v1.5",
+				},
+				"backport-done/1.5": {
+					Project: "https://github.com/orgs/cilium/projects/92",
+					Column:  "Backport done to v1.5",
+				},
+			},
+		},
 		RequireMsgsInCommit: []MsgInCommit{
 			{
 				Msg:    "Signed-off-by",
@@ -55,6 +89,21 @@ func TestConfigParser(t *testing.T) {
 				},
 			},
 		},
+		AutoMerge: &AutoMerge{
+			Enabled:          true,
+			Label:            "ready-to-merge",
+			MinimalApprovals: 1,
+			Branches: []AutoMergeBranch{
+				{
+					Branch:           "v*",
+					MinimalApprovals: 2,
+				},
+				{
+					Branch:  "ft/*",
+					Enabled: new(false),
+				},
+			},
+		},
 	}
 
 	file, err := os.Open("testdata/config.yml")
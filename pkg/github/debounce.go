@@ -0,0 +1,199 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// remainingChecksTTL is the amount of time the required checks that did not
+// pass in the last evaluation of a commit are remembered.
+const remainingChecksTTL = 24 * time.Hour
+
+// CIDebouncer collapses the auto-merge evaluations triggered by bursts of
+// status and check run events of the same commit into a single evaluation per
+// PR. It is safe for concurrent use.
+type CIDebouncer struct {
+	window time.Duration
+
+	mu sync.Mutex
+	// pending maps a commit to the evaluations waiting for its debounce
+	// window to expire, by PR number.
+	pending map[string]map[int]debouncedEval
+	// remaining maps a commit to the required checks that did not pass in
+	// its last evaluation.
+	remaining map[string]remainingChecks
+}
+
+// debouncedEval is an evaluation waiting for a debounce window to expire,
+// along with the client of the last event that triggered it, whose scheduler
+// runs the evaluation once the window expires.
+type debouncedEval struct {
+	client *Client
+	eval   func(c *Client) error
+}
+
+type remainingChecks struct {
+	names     map[string]struct{}
+	evaluated time.Time
+}
+
+// NewCIDebouncer returns a CIDebouncer that delays evaluations by the given
+// window.
+func NewCIDebouncer(window time.Duration) *CIDebouncer {
+	return &CIDebouncer{
+		window:    window,
+		pending:   map[string]map[int]debouncedEval{},
+		remaining: map[string]remainingChecks{},
+	}
+}
+
+// SetCIDebouncer sets the debouncer of the evaluations triggered by status
+// and check run events.
+func (c *Client) SetCIDebouncer(d *CIDebouncer) {
+	c.ciDebouncer = d
+}
+
+func commitKey(owner, repoName, sha string) string {
+	return fmt.Sprintf("%s/%s@%s", owner, repoName, sha)
+}
+
+// setRemaining records the required checks of the commit that did not pass.
+func (d *CIDebouncer) setRemaining(key string, checks CIChecks) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for k, r := range d.remaining {
+		if time.Since(r.evaluated) > remainingChecksTTL {
+			delete(d.remaining, k)
+		}
+	}
+	if len(checks) == 0 {
+		delete(d.remaining, key)
+		return
+	}
+	names := map[string]struct{}{}
+	for _, cc := range checks {
+		names[cc.Name] = struct{}{}
+	}
+	d.remaining[key] = remainingChecks{names: names, evaluated: time.Now()}
+}
+
+// completes returns true if 'check' was the only required check of the commit
+// that did not pass in its last evaluation.
+func (d *CIDebouncer) completes(key, check string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r, ok := d.remaining[key]
+	if !ok || len(r.names) != 1 {
+		return false
+	}
+	_, ok = r.names[check]
+	return ok
+}
+
+// add queues the evaluation of the given PR until the debounce window of the
+// commit expires, replacing any evaluation of the same PR already queued. It
+// returns false if the commit already had a window open.
+func (d *CIDebouncer) add(key string, prNumber int, eval debouncedEval) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	evals, ok := d.pending[key]
+	if !ok {
+		evals = map[int]debouncedEval{}
+		d.pending[key] = evals
+	}
+	evals[prNumber] = eval
+	return !ok
+}
+
+// take removes and returns the evaluations queued for the commit.
+func (d *CIDebouncer) take(key string) map[int]debouncedEval {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	evals := d.pending[key]
+	delete(d.pending, key)
+	return evals
+}
+
+// expire schedules the evaluations queued for the commit as detached
+// evaluations, since the events that triggered them were already handled.
+func (d *CIDebouncer) expire(key string) {
+	for prNumber, e := range d.take(key) {
+		if err := e.client.schedule(prNumber, true, e.eval); err != nil {
+			e.client.log.Err(err).Fields(map[string]interface{}{
+				"commit":    key,
+				"pr-number": prNumber,
+			}).Msg("Unable to evaluate PR after debounce window")
+		}
+	}
+}
+
+// Flush schedules all evaluations waiting for their debounce window to expire
+// right away, e.g., before shutting down.
+func (d *CIDebouncer) Flush() {
+	d.mu.Lock()
+	keys := slices.Collect(maps.Keys(d.pending))
+	d.mu.Unlock()
+	for _, key := range keys {
+		d.expire(key)
+	}
+}
+
+// debounceAutoMerge schedules the auto-merge evaluation of a PR triggered by
+// the given required check of the commit reporting the given state. Without a
+// debouncer, or if the check was the last one of the commit that did not
+// pass, the evaluation, and any other evaluation queued for the commit, is
+// scheduled right away. Otherwise it is scheduled once the debounce window of
+// the commit expires, along with the evaluations of all events received in
+// the meantime.
+func (c *Client) debounceAutoMerge(owner, repoName, sha, check string, state CIState, prNumber int, eval func(c *Client) error) error {
+	d := c.ciDebouncer
+	if d == nil {
+		return c.schedule(prNumber, false, eval)
+	}
+
+	key := commitKey(owner, repoName, sha)
+	if state == CIStatePassed && d.completes(key, check) {
+		c.log.Info().Fields(map[string]interface{}{
+			"commit": key,
+			"check":  check,
+		}).Msg("Last required check passed, evaluating right away")
+		evals := d.take(key)
+		delete(evals, prNumber)
+		if err := c.schedule(prNumber, false, eval); err != nil {
+			return err
+		}
+		for n, e := range evals {
+			if err := c.schedule(n, false, e.eval); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !d.add(key, prNumber, debouncedEval{client: c, eval: eval}) {
+		// Collapsed into the evaluation already waiting for the window of
+		// this commit.
+		return nil
+	}
+	time.AfterFunc(d.window, func() {
+		d.expire(key)
+	})
+	return nil
+}
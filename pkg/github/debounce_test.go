@@ -0,0 +1,112 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_debounceAutoMerge(t *testing.T) {
+	log := zerolog.Nop()
+	d := NewCIDebouncer(50 * time.Millisecond)
+	c := &Client{log: &log, ciDebouncer: d}
+	key := commitKey("cilium", "cilium", "abc")
+
+	var evals atomic.Int32
+	eval := func(*Client) error {
+		evals.Add(1)
+		return nil
+	}
+
+	// A burst of events of the same PR is collapsed into a single
+	// evaluation once the window expires.
+	for i := 0; i < 10; i++ {
+		err := c.debounceAutoMerge("cilium", "cilium", "abc", "build", CIStatePending, 1, eval)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(0), evals.Load())
+	assert.Eventually(t, func() bool { return evals.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	// The last required check passing evaluates right away, along with the
+	// evaluations waiting for the window.
+	d.setRemaining(key, CIChecks{{Name: "test", State: CIStatePending}})
+	err := c.debounceAutoMerge("cilium", "cilium", "abc", "build", CIStatePassed, 2, eval)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), evals.Load())
+	err = c.debounceAutoMerge("cilium", "cilium", "abc", "test", CIStatePassed, 1, eval)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), evals.Load())
+
+	// Nothing is left to evaluate once the window expires.
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(3), evals.Load())
+}
+
+func TestClient_debounceAutoMerge_scheduled(t *testing.T) {
+	type scheduled struct {
+		prNumber int
+		detached bool
+	}
+	var (
+		mu    sync.Mutex
+		calls []scheduled
+	)
+	log := zerolog.Nop()
+	d := NewCIDebouncer(time.Hour)
+	c := &Client{log: &log, ciDebouncer: d}
+	evalClient := &Client{log: &log}
+	c.SetScheduler(func(prNumber int, detached bool, eval func(c *Client) error) error {
+		mu.Lock()
+		calls = append(calls, scheduled{prNumber, detached})
+		mu.Unlock()
+		return eval(evalClient)
+	})
+	var evaluatedWith []*Client
+	eval := func(c *Client) error {
+		evaluatedWith = append(evaluatedWith, c)
+		return nil
+	}
+
+	// The evaluations of the last required check passing are part of the
+	// event.
+	key := commitKey("cilium", "cilium", "abc")
+	d.setRemaining(key, CIChecks{{Name: "build", State: CIStatePending}})
+	err := c.debounceAutoMerge("cilium", "cilium", "abc", "build", CIStatePassed, 1, eval)
+	assert.NoError(t, err)
+	assert.Equal(t, []scheduled{{1, false}}, calls)
+
+	// The evaluations waiting for the window are detached once flushed, and
+	// run with the client given by the scheduler.
+	calls = nil
+	for _, prNumber := range []int{2, 2, 3} {
+		err := c.debounceAutoMerge("cilium", "cilium", "def", "build", CIStatePending, prNumber, eval)
+		assert.NoError(t, err)
+	}
+	assert.Empty(t, calls)
+	d.Flush()
+	assert.ElementsMatch(t, []scheduled{{2, true}, {3, true}}, calls)
+	assert.Equal(t, []*Client{evalClient, evalClient, evalClient}, evaluatedWith)
+
+	// Nothing is left to flush.
+	calls = nil
+	d.Flush()
+	assert.Empty(t, calls)
+}
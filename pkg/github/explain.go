@@ -0,0 +1,127 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Explanation is the outcome of the evaluation of the rules of a config for a
+// PR.
+type Explanation struct {
+	Owner   string `json:"owner"`
+	Repo    string `json:"repo"`
+	Number  int    `json:"number"`
+	HeadSHA string `json:"head-sha"`
+	// ConfigPath is the path of the config the rules were loaded from.
+	ConfigPath string            `json:"config-path"`
+	Rules      []RuleExplanation `json:"rules"`
+	// PlannedActions are the changes the evaluation would make to the PR.
+	PlannedActions []PlannedAction `json:"planned-actions,omitempty"`
+}
+
+// RuleExplanation is the outcome of the evaluation of a single rule.
+type RuleExplanation struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	// Reasons explain why the rule did not pass.
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// ExplainPR evaluates the rules of the given config, loaded from cfgPath, for
+// the given PR of the client's repository. The client is switched to shadow
+// mode so that the changes the evaluation would make are only recorded as
+// planned actions.
+func (c *Client) ExplainPR(cfg PRBlockerConfig, cfgPath string, prNumber int) (*Explanation, error) {
+	c.SetShadowMode(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	pr, _, err := c.GHClient.PullRequests.Get(ctx, c.orgName, c.repoName, prNumber)
+	if err != nil {
+		return nil, err
+	}
+	prLabels := parseGHLabels(pr.Labels)
+	e := &Explanation{
+		Owner:      c.orgName,
+		Repo:       c.repoName,
+		Number:     prNumber,
+		HeadSHA:    pr.GetHead().GetSHA(),
+		ConfigPath: cfgPath,
+	}
+
+	if len(cfg.BlockPRWith.LabelsUnset) != 0 || len(cfg.BlockPRWith.LabelsSet) != 0 || len(cfg.MoveToProjectsForLabelsXORed) != 0 {
+		blockPR, reasons, err := cfg.BlockPRWith.IsBlocked(prLabels)
+		if err != nil {
+			return nil, err
+		}
+		if xoredReasons := cfg.MoveToProjectsForLabelsXORed.BlockReasons(prLabels); len(xoredReasons) != 0 {
+			blockPR = true
+			reasons = append(reasons, xoredReasons...)
+		}
+		e.Rules = append(e.Rules, RuleExplanation{
+			Rule:    ruleBlockPRWith,
+			Passed:  !blockPR,
+			Reasons: reasons,
+		})
+	}
+
+	if len(cfg.RequireMsgsInCommit) != 0 {
+		r := RuleExplanation{Rule: ruleRequireMsgsInCommit}
+		for _, msgRequired := range cfg.RequireMsgsInCommit {
+			re, err := msgRequired.Regexp()
+			if err != nil {
+				return nil, err
+			}
+			commits, err := c.commitMatches(c.orgName, c.repoName, prNumber, re)
+			if err != nil {
+				return nil, err
+			}
+			if len(commits) != 0 {
+				r.Reasons = append(r.Reasons, fmt.Sprintf("commits %s do not match %q", strings.Join(commits, ", "), re))
+			}
+		}
+		r.Passed = len(r.Reasons) == 0
+		e.Rules = append(e.Rules, r)
+	}
+
+	if autoMergeCfg, ok := cfg.AutoMerge.ForBranch(pr.GetBase().GetRef()); ok {
+		r := RuleExplanation{Rule: ruleAutoMerge}
+		switch {
+		case pr.GetState() == "closed":
+			r.Reasons = []string{"PR is closed"}
+		case pr.GetDraft():
+			r.Reasons = []string{"PR is a draft"}
+		default:
+			status, err := c.autoMerge(autoMergeCfg, c.orgName, c.repoName, pr.GetBase(), pr.GetHead(), prNumber, prLabels, nil)
+			if err != nil {
+				return nil, err
+			}
+			if status == nil {
+				r.Reasons = []string{"head commit has no committer date"}
+			} else {
+				r.Passed = status.ready()
+				r.Reasons = status.reasons()
+			}
+		}
+		e.Rules = append(e.Rules, r)
+	}
+
+	e.PlannedActions = c.PlannedActions()
+	return e, nil
+}
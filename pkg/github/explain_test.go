@@ -0,0 +1,117 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestClient_ExplainPR(t *testing.T) {
+	const cfgYAML = `
+require-msgs-in-commit:
+  - msg: "Signed-off-by"
+block-pr-with:
+  labels-unset:
+  - regex-label: "release-note/.*"
+    set-labels:
+    - "dont-merge/needs-release-note"
+  labels-set:
+  - regex-label: "dont-merge/hold"
+    helper: "PR is on hold"
+auto-merge:
+  enabled: true
+  label: "ready-to-merge"
+`
+	tests := []struct {
+		name    string
+		draft   bool
+		labels  []string
+		message string
+		status  string
+		want    []RuleExplanation
+		// wantActions are the actions planned by the evaluation.
+		wantActions []string
+	}{
+		{
+			name:    "blocked",
+			labels:  []string{"dont-merge/hold"},
+			message: "Fix the bug",
+			status:  "pending",
+			want: []RuleExplanation{
+				{Rule: ruleBlockPRWith, Reasons: []string{"PR is on hold"}},
+				{Rule: ruleRequireMsgsInCommit, Reasons: []string{`commits abc do not match "Signed-off-by"`}},
+				{Rule: ruleAutoMerge, Reasons: []string{"required check ci/build: pending"}},
+			},
+			wantActions: []string{"create-check-run"},
+		},
+		{
+			name:    "ready",
+			labels:  []string{"release-note/bug"},
+			message: "Fix the bug\n\nSigned-off-by: Alice <alice@example.com>",
+			status:  "success",
+			want: []RuleExplanation{
+				{Rule: ruleBlockPRWith, Passed: true},
+				{Rule: ruleRequireMsgsInCommit, Passed: true},
+				{Rule: ruleAutoMerge, Passed: true},
+			},
+			wantActions: []string{"add-labels", "create-check-run"},
+		},
+		{
+			name:    "draft",
+			draft:   true,
+			labels:  []string{"release-note/bug"},
+			message: "Fix the bug\n\nSigned-off-by: Alice <alice@example.com>",
+			status:  "success",
+			want: []RuleExplanation{
+				{Rule: ruleBlockPRWith, Passed: true},
+				{Rule: ruleRequireMsgsInCommit, Passed: true},
+				{Rule: ruleAutoMerge, Reasons: []string{"PR is a draft"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			srv.SetBranchProtection("cilium", "cilium", "main", "ci/build")
+			srv.AddPR("cilium", "cilium", githubtest.PR{
+				Number:  1,
+				Author:  "alice",
+				Draft:   tt.draft,
+				Labels:  tt.labels,
+				Commits: []githubtest.Commit{{SHA: "abc", Message: tt.message, Author: "alice"}},
+			})
+			srv.SetStatus("cilium", "cilium", "abc", "ci/build", tt.status)
+			srv.AddReview("cilium", "cilium", 1, "bob", "APPROVED", "abc")
+			var cfg PRBlockerConfig
+			assert.NoError(t, yaml.Unmarshal([]byte(cfgYAML), &cfg))
+
+			e, err := c.ExplainPR(cfg, ".github/maintainers-little-helper.yaml", 1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, e.Rules)
+			var actions []string
+			for _, a := range e.PlannedActions {
+				actions = append(actions, a.Action)
+			}
+			assert.Equal(t, tt.wantActions, actions)
+			// The planned actions are not performed.
+			assert.Equal(t, tt.labels, srv.Labels("cilium", "cilium", 1))
+			assert.Nil(t, srv.CheckRun("cilium", "cilium", "abc", autoMergeStatusCheckName))
+		})
+	}
+}
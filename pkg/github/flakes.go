@@ -16,6 +16,7 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -140,6 +141,7 @@ prFailList:
 				return "", nil, nil, fmt.Errorf("unable to comment on the GH issue #%d: %w", ghIssueNumber, err)
 			}
 			flakesFound[ghIssueNumber] = append(flakesFound[ghIssueNumber], fmt.Sprintf("%.2f", 100*similarity))
+			c.metrics.flakeTriaged(flakeKnownIssue, similarity)
 			continue
 		}
 
@@ -178,6 +180,11 @@ prFailList:
 
 					// Create a GH issue or re-open it if the issue was closed
 					issueNumber, err := c.CreateIssue(ctx, c.orgName, c.repoName, title, body, flakeCfg.IssueTracker.IssueLabels)
+					if errors.Is(err, errIssuePlanned) {
+						// The flake can not be tracked without the number
+						// of its issue.
+						continue prFailList
+					}
 					if err != nil {
 						return "", nil, nil, fmt.Errorf("unable to create GH issue: %w", err)
 					}
@@ -194,6 +201,7 @@ prFailList:
 						Test:  prFailure.Test,
 					}
 					flakesFound[issueNumber] = append(flakesFound[issueNumber], fmt.Sprintf("%.2f", 100*similarity))
+					c.metrics.flakeTriaged(flakeNewIssue, similarity)
 					continue prFailList
 				}
 			}
@@ -204,6 +212,7 @@ prFailList:
 		}).Msg("Not found flake in GH Issue")
 
 		flakesNotFound = append(flakesNotFound, prFailure)
+		c.metrics.flakeTriaged(flakeNotFound, 0)
 	}
 
 	return prJobName, flakesFound, flakesNotFound, nil
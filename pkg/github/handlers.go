@@ -26,6 +26,12 @@ import (
 	gh "github.com/google/go-github/v84/github"
 )
 
+// IsBaseRetargeted returns true if the event is the edition of the base branch
+// of the PR.
+func IsBaseRetargeted(pre *gh.PullRequestEvent) bool {
+	return pre.GetAction() == "edited" && pre.GetChanges().GetBase() != nil
+}
+
 func (c *Client) HandlePullRequestEvent(cfg PRBlockerConfig, pre *gh.PullRequestEvent) error {
 	pr := pre.GetPullRequest()
 	owner := pr.Base.Repo.GetOwner().GetLogin()
@@ -37,11 +43,19 @@ func (c *Client) HandlePullRequestEvent(cfg PRBlockerConfig, pre *gh.PullRequest
 		"pr-number": prNumber,
 	}).Msg("Action triggered from PR")
 
+	// Only PRs retargeted to a different base branch need to be evaluated
+	// again, as if they left draft, since their configuration and their
+	// required checks might have changed.
+	if action == "edited" && !IsBaseRetargeted(pre) {
+		return nil
+	}
+
 	prLabels := parseGHLabels(pr.Labels)
 
 	// Autolabel PRs as soon they are created
-	if len(cfg.AutoLabel) != 0 { // We only auto-label PRs if when they are open / reopen
-		if action == "opened" || action == "reopened" {
+	if len(cfg.AutoLabel) != 0 { // We only auto-label PRs when they are (re)opened, leave draft or are retargeted
+		switch action {
+		case "opened", "reopened", "ready_for_review", "edited":
 			err := c.AutoLabel(cfg.AutoLabel, owner, repoName, prNumber, prLabels)
 			if err != nil {
 				return err
@@ -49,11 +63,23 @@ func (c *Client) HandlePullRequestEvent(cfg PRBlockerConfig, pre *gh.PullRequest
 		}
 	}
 
+	// Add PRs to the project as soon they are created
+	if cfg.ProjectColumn.IsSet() {
+		if action == "opened" || action == "reopened" {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			err := c.setProjectColumn(ctx, ruleProject, cfg.ProjectColumn, prNumber, pr.GetNodeID())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// Check for msgs in commits
 	if len(cfg.RequireMsgsInCommit) != 0 {
 		if pr.GetState() != "closed" {
 			switch action {
-			case "opened", "reopened", "synchronize":
+			case "opened", "reopened", "synchronize", "ready_for_review", "edited":
 				err := c.CommitContains(cfg.RequireMsgsInCommit, owner, repoName, prNumber)
 				if err != nil {
 					return err
@@ -63,16 +89,11 @@ func (c *Client) HandlePullRequestEvent(cfg PRBlockerConfig, pre *gh.PullRequest
 	}
 
 	// Block PRs if they miss or have particular labels set.
-	if len(cfg.BlockPRWith.LabelsUnset) != 0 || len(cfg.BlockPRWith.LabelsSet) != 0 {
+	if len(cfg.BlockPRWith.LabelsUnset) != 0 || len(cfg.BlockPRWith.LabelsSet) != 0 || len(cfg.MoveToProjectsForLabelsXORed) != 0 {
 		if pr.GetState() != "closed" {
 			switch action {
-			case "labeled", "unlabeled", "synchronize", "opened", "reopened":
-				blockPR, blockReasons, err := c.BlockPRWith(cfg.BlockPRWith, owner, repoName, prNumber, prLabels)
-				if err != nil {
-					return err
-				}
-				// Update the mergeability checker
-				err = c.UpdateMergeabilityCheck(owner, repoName, prNumber, pr.GetHead(), blockPR, blockReasons)
+			case "labeled", "unlabeled", "synchronize", "opened", "reopened", "ready_for_review", "edited":
+				err := c.updateMergeability(cfg, owner, repoName, pr, prLabels)
 				if err != nil {
 					return err
 				}
@@ -80,30 +101,40 @@ func (c *Client) HandlePullRequestEvent(cfg PRBlockerConfig, pre *gh.PullRequest
 		}
 	}
 
-	// if len(cfg.AutoMerge.Label) != 0 {
-	if true {
+	// Move PRs between project columns depending on which labels are set.
+	if len(cfg.MoveToProjectsForLabelsXORed) != 0 {
+		switch action {
+		case "labeled", "unlabeled":
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			err := c.MoveToProjectsForLabelsXORed(ctx, cfg.MoveToProjectsForLabelsXORed, prNumber, pr.GetNodeID(), pre.GetLabel().GetName(), prLabels)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	autoMergeCfg, autoMerge := cfg.AutoMerge.ForBranch(pr.GetBase().GetRef())
+	if autoMerge {
 		switch action {
 		case "synchronize":
-			// Remove ready-to-merge label if it is present and the developer
+			// Remove the auto-merge label if it is present and the developer
 			// synchronized the PR
-			if _, ok := prLabels[cfg.AutoMerge.Label]; ok {
-				_, err := c.GHClient.Issues.RemoveLabelForIssue(
-					context.Background(), owner, repoName, prNumber, cfg.AutoMerge.Label)
+			if _, ok := prLabels[autoMergeCfg.Label]; ok {
+				err := c.removeLabel(context.Background(), ruleAutoMerge, owner, repoName, prNumber, autoMergeCfg.Label)
 				if err != nil {
 					return err
 				}
-				delete(prLabels, cfg.AutoMerge.Label)
+				delete(prLabels, autoMergeCfg.Label)
 			}
 		}
 		switch action {
-		case "labeled", "unlabeled", "synchronize":
-			cfg.AutoMerge.Label = "ready-to-merge"
-			cfg.AutoMerge.MinimalApprovals = 1
-			if pre.GetLabel().GetName() == cfg.AutoMerge.Label {
+		case "labeled", "unlabeled", "synchronize", "ready_for_review", "edited":
+			if pre.GetLabel().GetName() == autoMergeCfg.Label {
 				return nil
 			}
 			if !pr.GetDraft() {
-				err := c.AutoMerge(cfg.AutoMerge, owner, repoName, pr.GetBase(), pr.GetHead(), prNumber, prLabels, nil)
+				err := c.AutoMerge(autoMergeCfg, owner, repoName, pr.GetBase(), pr.GetHead(), prNumber, prLabels, nil)
 				if err != nil {
 					return err
 				}
@@ -127,12 +158,10 @@ func (c *Client) HandlePullRequestReviewEvent(cfg PRBlockerConfig, pre *gh.PullR
 
 	prLabels := parseGHLabels(pr.Labels)
 
-	// if len(cfg.AutoMerge.Label) != 0 {
-	if true {
-		cfg.AutoMerge.Label = "ready-to-merge"
-		cfg.AutoMerge.MinimalApprovals = 1
+	autoMergeCfg, autoMerge := cfg.AutoMerge.ForBranch(pr.GetBase().GetRef())
+	if autoMerge {
 		if !pr.GetDraft() {
-			err := c.AutoMerge(cfg.AutoMerge, owner, repoName, pr.GetBase(), pr.GetHead(), prNumber, prLabels, pre.Review)
+			err := c.AutoMerge(autoMergeCfg, owner, repoName, pr.GetBase(), pr.GetHead(), prNumber, prLabels, pre.Review)
 			if err != nil {
 				return err
 			}
@@ -141,15 +170,119 @@ func (c *Client) HandlePullRequestReviewEvent(cfg PRBlockerConfig, pre *gh.PullR
 	return nil
 }
 
+// updateMergeability evaluates the rules that block the PR and updates its
+// Mergeability check.
+func (c *Client) updateMergeability(cfg PRBlockerConfig, owner, repoName string, pr *gh.PullRequest, prLabels PRLabels) error {
+	blockPR, blockReasons, err := c.BlockPRWith(cfg.BlockPRWith, owner, repoName, pr.GetNumber(), prLabels)
+	if err != nil {
+		return err
+	}
+	// Block PRs that have conflicting labels set for the same branch.
+	if xoredReasons := cfg.MoveToProjectsForLabelsXORed.BlockReasons(prLabels); len(xoredReasons) != 0 {
+		blockPR = true
+		blockReasons = append(blockReasons, xoredReasons...)
+	}
+	// Update the mergeability checker
+	return c.UpdateMergeabilityCheck(owner, repoName, pr.GetNumber(), pr.GetHead(), blockPR, blockReasons)
+}
+
+// reevaluatePR updates the Mergeability check, and re-evaluates the
+// auto-merge conditions, of the given PR with the config at its base SHA.
+func (c *Client) reevaluatePR(owner, repoName string, prNumber int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	pr, _, err := c.GHClient.PullRequests.Get(ctx, owner, repoName, prNumber)
+	if err != nil {
+		return err
+	}
+	if pr.GetState() == "closed" {
+		return nil
+	}
+	cfg, err := c.baseConfig(pr)
+	if err != nil || cfg == nil {
+		return err
+	}
+	c.log.Info().Fields(map[string]interface{}{
+		"pr-number": prNumber,
+	}).Msg("Re-evaluating PR")
+
+	return c.evaluatePR(*cfg, owner, repoName, pr, parseGHLabels(pr.Labels))
+}
+
+// evaluatePR updates the Mergeability check, and evaluates the auto-merge
+// conditions, of the given open PR.
+func (c *Client) evaluatePR(cfg PRBlockerConfig, owner, repoName string, pr *gh.PullRequest, prLabels PRLabels) error {
+	if len(cfg.BlockPRWith.LabelsUnset) != 0 || len(cfg.BlockPRWith.LabelsSet) != 0 || len(cfg.MoveToProjectsForLabelsXORed) != 0 {
+		err := c.updateMergeability(cfg, owner, repoName, pr, prLabels)
+		if err != nil {
+			return err
+		}
+	}
+	if autoMergeCfg, ok := cfg.AutoMerge.ForBranch(pr.GetBase().GetRef()); ok && !pr.GetDraft() {
+		return c.AutoMerge(autoMergeCfg, owner, repoName, pr.GetBase(), pr.GetHead(), pr.GetNumber(), prLabels, nil)
+	}
+	return nil
+}
+
+// ReconcilePR runs the same evaluation as the webhook handlers for the given
+// open PR, to repair the effects of missed webhooks. It is idempotent so it
+// does not comment again about the commit messages of the PR if their labels
+// are already set.
+func (c *Client) ReconcilePR(cfg PRBlockerConfig, pr *gh.PullRequest) error {
+	prLabels := parseGHLabels(pr.Labels)
+	if len(cfg.RequireMsgsInCommit) != 0 {
+		err := c.commitContains(cfg.RequireMsgsInCommit, c.orgName, c.repoName, pr.GetNumber(), prLabels)
+		if err != nil {
+			return err
+		}
+	}
+	return c.evaluatePR(cfg, c.orgName, c.repoName, pr, prLabels)
+}
+
+// ReevaluatePR runs ReconcilePR for the given PR of the client's repository.
+// Closed PRs are ignored.
+func (c *Client) ReevaluatePR(cfg PRBlockerConfig, prNumber int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	pr, _, err := c.GHClient.PullRequests.Get(ctx, c.orgName, c.repoName, prNumber)
+	if err != nil {
+		return err
+	}
+	if pr.GetState() == "closed" {
+		return nil
+	}
+	return c.ReconcilePR(cfg, pr)
+}
+
+// HandlePullRequestReviewThreadEvent re-evaluates the auto-merge conditions
+// of the PR when one of its review threads is resolved or unresolved.
+func (c *Client) HandlePullRequestReviewThreadEvent(cfg PRBlockerConfig, e *gh.PullRequestReviewThreadEvent) error {
+	pr := e.GetPullRequest()
+	owner := pr.Base.Repo.GetOwner().GetLogin()
+	repoName := *pr.Base.Repo.Name
+	prNumber := pr.GetNumber()
+	action := e.GetAction()
+	c.log.Info().Fields(map[string]interface{}{
+		"action":    action,
+		"pr-number": prNumber,
+	}).Msg("Action triggered from PR review thread")
+
+	autoMergeCfg, autoMerge := cfg.AutoMerge.ForBranch(pr.GetBase().GetRef())
+	if !autoMerge || !autoMergeCfg.RequireResolvedThreads || pr.GetDraft() {
+		return nil
+	}
+	prLabels := parseGHLabels(pr.Labels)
+	return c.AutoMerge(autoMergeCfg, owner, repoName, pr.GetBase(), pr.GetHead(), prNumber, prLabels, nil)
+}
+
+// HandleStatusEvent schedules the auto-merge evaluation of the PRs of the
+// commit of the status, and triages the flakes of failed Jenkins jobs. The
+// given config, of the default branch of the repository, only decides what is
+// evaluated: the PRs are evaluated with the config at their base SHA.
 func (c *Client) HandleStatusEvent(cfg PRBlockerConfig, se *gh.StatusEvent) error {
 	owner := se.Repo.GetOwner().GetLogin()
 	repoName := *se.Repo.Name
 
-	if true {
-		cfg.AutoMerge.Label = "ready-to-merge"
-		cfg.AutoMerge.MinimalApprovals = 1
-	}
-
 	var (
 		cancels               []context.CancelFunc
 		urlFails              []string
@@ -213,11 +346,11 @@ func (c *Client) HandleStatusEvent(cfg PRBlockerConfig, se *gh.StatusEvent) erro
 				continue
 			}
 
-			prLabels := parseGHLabels(pr.Labels)
-
-			err = c.AutoMerge(cfg.AutoMerge, owner, repoName, pr.GetBase(), pr.GetHead(), pr.GetNumber(), prLabels, nil)
-			if err != nil {
-				return err
+			if _, ok := cfg.AutoMerge.ForBranch(pr.GetBase().GetRef()); ok {
+				err = c.debounceAutoMerge(owner, repoName, se.GetSHA(), se.GetContext(), commitStatusState(se.GetState()), pr.GetNumber(), autoMergeEval(pr.GetNumber()))
+				if err != nil {
+					return err
+				}
 			}
 
 			if triage {
@@ -241,10 +374,60 @@ func (c *Client) HandleStatusEvent(cfg PRBlockerConfig, se *gh.StatusEvent) erro
 	return nil
 }
 
+// isOwnCheckRun returns true if the check run is one of the check runs
+// created by us. Other apps might create check runs with the same names so,
+// unless the ID of our app is unknown, the app of the check run must be ours.
+func (c *Client) isOwnCheckRun(cr *gh.CheckRun) bool {
+	if c.appID != 0 && cr.GetApp().GetID() != c.appID {
+		return false
+	}
+	switch cr.GetName() {
+	case mergeabilityCheckName, autoMergeStatusCheckName:
+		return true
+	}
+	return false
+}
+
+// requestedActionIdentifier returns the identifier of the action requested in
+// the check run event, or an empty string if there is none.
+func requestedActionIdentifier(e *gh.CheckRunEvent) string {
+	if ra := e.GetRequestedAction(); ra != nil {
+		return ra.Identifier
+	}
+	return ""
+}
+
+// HandleCheckRunEvent schedules the auto-merge evaluation of the PRs of the
+// check run, or their full re-evaluation if one of our check runs is re-run.
+// As for status events, the given config is the one of the default branch of
+// the repository, and the PRs are evaluated with the config at their base SHA.
 func (c *Client) HandleCheckRunEvent(cfg PRBlockerConfig, e *gh.CheckRunEvent) error {
-	cfg.AutoMerge.Label = "ready-to-merge"
-	cfg.AutoMerge.MinimalApprovals = 1
+	switch e.GetAction() {
+	case "completed":
+	case "rerequested", "requested_action":
+		// Someone clicked "Re-run" or "Re-evaluate" in one of our check
+		// runs.
+		if !c.isOwnCheckRun(e.GetCheckRun()) {
+			return nil
+		}
+		if e.GetAction() == "requested_action" && requestedActionIdentifier(e) != reevaluateActionID {
+			return nil
+		}
+		for _, pr := range e.GetCheckRun().PullRequests {
+			if err := c.scheduleReevaluation(pr.GetNumber()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
 
+	// Ignore the check runs created by us, otherwise each update of the
+	// auto-merge status would trigger a new evaluation.
+	if c.isOwnCheckRun(e.GetCheckRun()) {
+		return nil
+	}
 	for _, pr := range e.GetCheckRun().PullRequests {
 		prOrgName, prRepoName, err := ownerRepoFromRepositoryURL(pr.GetBase().GetRepo().GetURL())
 		if err != nil {
@@ -260,9 +443,15 @@ func (c *Client) HandleCheckRunEvent(cfg PRBlockerConfig, e *gh.CheckRunEvent) e
 			continue
 		}
 
-		prLabels := parseGHLabels(pr.Labels)
+		autoMergeCfg, ok := cfg.AutoMerge.ForBranch(pr.GetBase().GetRef())
+		if !ok {
+			continue
+		}
 
-		if err := c.AutoMerge(cfg.AutoMerge, prOrgName, prRepoName, pr.GetBase(), pr.GetHead(), pr.GetNumber(), prLabels, nil); err != nil {
+		cr := e.GetCheckRun()
+		state := checkRunState(cr.GetStatus(), cr.GetConclusion(), autoMergeCfg.PassingConclusions)
+		err = c.debounceAutoMerge(prOrgName, prRepoName, cr.GetHeadSHA(), cr.GetName(), state, pr.GetNumber(), autoMergeEval(pr.GetNumber()))
+		if err != nil {
 			return fmt.Errorf("failed to automerge: %w", err)
 		}
 	}
@@ -270,6 +459,20 @@ func (c *Client) HandleCheckRunEvent(cfg PRBlockerConfig, e *gh.CheckRunEvent) e
 	return nil
 }
 
+// HandleCheckSuiteEvent re-evaluates the PRs of our check suite when someone
+// re-runs all of its check runs.
+func (c *Client) HandleCheckSuiteEvent(e *gh.CheckSuiteEvent) error {
+	if e.GetAction() != "rerequested" {
+		return nil
+	}
+	for _, pr := range e.GetCheckSuite().PullRequests {
+		if err := c.scheduleReevaluation(pr.GetNumber()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // IsNotFound returns true if the given error is a NotFound.
 func IsNotFound(err error) bool {
 	return IsHTTPErrorCode(err, http.StatusNotFound)
@@ -18,9 +18,37 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cilium/github-actions/pkg/githubtest"
+	gh "github.com/google/go-github/v84/github"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
 
+// newFakeClient returns a client of the "cilium/cilium" repository of a fake
+// GitHub API. The test fails if the client uses endpoints not modelled by the
+// fake.
+func newFakeClient(t *testing.T) (*githubtest.Server, *Client) {
+	srv := githubtest.NewServer()
+	t.Cleanup(func() {
+		assert.Empty(t, srv.Unhandled())
+		srv.Close()
+	})
+	log := zerolog.Nop()
+	c := NewClientFromGHClient(srv.Client(), srv.V4Client(), "cilium", "cilium", &log)
+	c.SetAppID(githubtest.AppID)
+	return srv, c
+}
+
+// testConfigPath is the path of the config of the repositories of the fake.
+const testConfigPath = ".github/maintainers-little-helper.yaml"
+
+// setFakeConfig sets the config of the "cilium/cilium" repository of the fake,
+// at all refs.
+func setFakeConfig(t *testing.T, srv *githubtest.Server, cfg string) {
+	t.Setenv("CONFIG_PATHS", testConfigPath)
+	srv.SetFile("cilium", "cilium", testConfigPath, cfg)
+}
+
 func Test_ownerRepoFromRepositoryURL(t *testing.T) {
 	type args struct {
 		url string
@@ -62,3 +90,237 @@ func Test_ownerRepoFromRepositoryURL(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_HandlePullRequestEvent_reevaluated(t *testing.T) {
+	cfg := PRBlockerConfig{
+		AutoLabel: []string{"pending-review"},
+		BlockPRWith: BlockPRWith{
+			LabelsUnset: []PRLabelConfig{
+				{RegexLabel: "^release-note/", SetLabels: []string{"dont-merge/needs-release-note-label"}},
+			},
+		},
+		AutoMerge: &AutoMerge{Enabled: true},
+	}
+	tests := []struct {
+		name    string
+		action  string
+		changes *gh.EditChange
+		// evaluated is true if the PR is labeled and its checks created.
+		evaluated bool
+	}{
+		{
+			name:      "ready for review",
+			action:    "ready_for_review",
+			evaluated: true,
+		},
+		{
+			name:   "title edited",
+			action: "edited",
+			changes: &gh.EditChange{
+				Title: &gh.EditTitle{From: new("Fix the bug")},
+			},
+			evaluated: false,
+		},
+		{
+			name:   "retargeted",
+			action: "edited",
+			changes: &gh.EditChange{
+				Base: &gh.EditBase{Ref: &gh.EditRef{From: new("v1.16")}},
+			},
+			evaluated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			srv.AddPR("cilium", "cilium", githubtest.PR{
+				Commits: []githubtest.Commit{{SHA: "abc"}},
+			})
+
+			err := c.HandlePullRequestEvent(cfg, &gh.PullRequestEvent{
+				Action:      new(tt.action),
+				Changes:     tt.changes,
+				PullRequest: srv.PullRequest("cilium", "cilium", 1),
+			})
+			assert.NoError(t, err)
+			if !tt.evaluated {
+				assert.Empty(t, srv.Labels("cilium", "cilium", 1))
+				assert.Empty(t, srv.CheckRuns("cilium", "cilium", "abc"))
+				return
+			}
+			assert.Equal(t, []string{"dont-merge/needs-release-note-label", "pending-review"}, srv.Labels("cilium", "cilium", 1))
+			assert.Equal(t, "failure", srv.CheckRun("cilium", "cilium", "abc", mergeabilityCheckName).GetConclusion())
+			assert.Equal(t, "neutral", srv.CheckRun("cilium", "cilium", "abc", autoMergeStatusCheckName).GetConclusion())
+		})
+	}
+}
+
+func TestClient_HandleCheckRunEvent(t *testing.T) {
+	cfg := PRBlockerConfig{AutoMerge: &AutoMerge{Enabled: true}}
+	prFrom := func(repoURL string) []*gh.PullRequest {
+		return []*gh.PullRequest{{
+			Number: new(1),
+			Base: &gh.PullRequestBranch{
+				Ref:  new("main"),
+				Repo: &gh.Repository{URL: new(repoURL)},
+			},
+			Head: &gh.PullRequestBranch{SHA: new("abc")},
+		}}
+	}
+	const (
+		ours     = "https://api.github.com/repos/cilium/cilium"
+		fork     = "https://api.github.com/repos/alice/cilium"
+		otherApp = 2
+	)
+	tests := []struct {
+		name            string
+		action          string
+		checkRun        string
+		appID           int64
+		requestedAction *gh.RequestedAction
+		repoURL         string
+		// evaluated is true if the auto-merge conditions of the PR are
+		// evaluated.
+		evaluated bool
+	}{
+		{
+			name:      "CI check run completed",
+			action:    "completed",
+			checkRun:  "ci/build",
+			appID:     otherApp,
+			repoURL:   ours,
+			evaluated: true,
+		},
+		{
+			name:     "CI check run of a fork completed",
+			action:   "completed",
+			checkRun: "ci/build",
+			appID:    otherApp,
+			repoURL:  fork,
+		},
+		{
+			name:     "own check run completed",
+			action:   "completed",
+			checkRun: autoMergeStatusCheckName,
+			appID:    githubtest.AppID,
+			repoURL:  ours,
+		},
+		{
+			name:      "check run of another app with our name completed",
+			action:    "completed",
+			checkRun:  autoMergeStatusCheckName,
+			appID:     otherApp,
+			repoURL:   ours,
+			evaluated: true,
+		},
+		{
+			name:      "own check run re-run",
+			action:    "rerequested",
+			checkRun:  mergeabilityCheckName,
+			appID:     githubtest.AppID,
+			repoURL:   ours,
+			evaluated: true,
+		},
+		{
+			name:     "check run of another app with our name re-run",
+			action:   "rerequested",
+			checkRun: mergeabilityCheckName,
+			appID:    otherApp,
+			repoURL:  ours,
+		},
+		{
+			name:            "own check run re-evaluated",
+			action:          "requested_action",
+			checkRun:        autoMergeStatusCheckName,
+			appID:           githubtest.AppID,
+			requestedAction: &gh.RequestedAction{Identifier: reevaluateActionID},
+			repoURL:         ours,
+			evaluated:       true,
+		},
+		{
+			name:            "unknown action requested",
+			action:          "requested_action",
+			checkRun:        autoMergeStatusCheckName,
+			appID:           githubtest.AppID,
+			requestedAction: &gh.RequestedAction{Identifier: "merge"},
+			repoURL:         ours,
+		},
+		{
+			name:     "no action requested",
+			action:   "requested_action",
+			checkRun: autoMergeStatusCheckName,
+			appID:    githubtest.AppID,
+			repoURL:  ours,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			setFakeConfig(t, srv, "auto-merge:\n  enabled: true\n")
+			srv.AddPR("cilium", "cilium", githubtest.PR{
+				Commits: []githubtest.Commit{{SHA: "abc"}},
+			})
+
+			err := c.HandleCheckRunEvent(cfg, &gh.CheckRunEvent{
+				Action: new(tt.action),
+				CheckRun: &gh.CheckRun{
+					Name:         new(tt.checkRun),
+					HeadSHA:      new("abc"),
+					Status:       new("completed"),
+					Conclusion:   new("success"),
+					App:          &gh.App{ID: new(tt.appID)},
+					PullRequests: prFrom(tt.repoURL),
+				},
+				RequestedAction: tt.requestedAction,
+			})
+			assert.NoError(t, err)
+			if tt.evaluated {
+				assert.NotNil(t, srv.CheckRun("cilium", "cilium", "abc", autoMergeStatusCheckName))
+			} else {
+				assert.Empty(t, srv.CheckRuns("cilium", "cilium", "abc"))
+			}
+		})
+	}
+}
+
+func TestClient_HandleCheckSuiteEvent(t *testing.T) {
+	tests := []struct {
+		name      string
+		action    string
+		evaluated bool
+	}{
+		{
+			name:      "re-run",
+			action:    "rerequested",
+			evaluated: true,
+		},
+		{
+			name:   "completed",
+			action: "completed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			setFakeConfig(t, srv, "auto-merge:\n  enabled: true\n")
+			srv.AddPR("cilium", "cilium", githubtest.PR{
+				Commits: []githubtest.Commit{{SHA: "abc"}},
+			})
+
+			err := c.HandleCheckSuiteEvent(&gh.CheckSuiteEvent{
+				Action: new(tt.action),
+				CheckSuite: &gh.CheckSuite{
+					HeadSHA:      new("abc"),
+					App:          &gh.App{ID: new(githubtest.AppID)},
+					PullRequests: []*gh.PullRequest{{Number: new(1)}},
+				},
+			})
+			assert.NoError(t, err)
+			if tt.evaluated {
+				assert.NotNil(t, srv.CheckRun("cilium", "cilium", "abc", autoMergeStatusCheckName))
+			} else {
+				assert.Empty(t, srv.CheckRuns("cilium", "cilium", "abc"))
+			}
+		})
+	}
+}
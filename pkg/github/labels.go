@@ -16,6 +16,7 @@ package github
 
 import (
 	"context"
+	"regexp"
 
 	gh "github.com/google/go-github/v84/github"
 )
@@ -31,6 +32,17 @@ func parseGHLabels(ghLabels []*gh.Label) PRLabels {
 	return lbls
 }
 
+// matchAny returns true if any of the labels matches the given regex.
+func (l PRLabels) matchAny(regex string) (bool, error) {
+	for lbl := range l {
+		matched, err := regexp.MatchString(regex, lbl)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
 // subslice returns true if all elements of 's1' are keys of 's2'.
 func subslice(s1 []string, s2 PRLabels) bool {
 	if len(s1) > len(s2) {
@@ -50,7 +62,5 @@ func (c *Client) AutoLabel(labels []string, owner string, repoName string, prNum
 		return nil
 	}
 
-	_, _, err := c.GHClient.Issues.AddLabelsToIssue(
-		context.Background(), owner, repoName, prNumber, labels)
-	return err
+	return c.addLabels(context.Background(), ruleAutoLabel, owner, repoName, prNumber, labels)
 }
@@ -0,0 +1,243 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/shurcooL/githubv4"
+)
+
+const (
+	// MergeModeMerge merges the PR through the REST API.
+	MergeModeMerge = "merge"
+	// MergeModeAutoMerge enables GitHub's native auto-merge in the PR.
+	MergeModeAutoMerge = "auto-merge"
+)
+
+type MergeConfig struct {
+	// Mode is either "merge", to merge the PR as soon as all conditions are
+	// met, or "auto-merge", to enable GitHub's native auto-merge in the PR.
+	Mode string `yaml:"mode"`
+	// Method is the merge method, one of "merge", "squash" or "rebase".
+	// Defaults to "merge".
+	Method string `yaml:"method,omitempty"`
+	// CommitTitle is a text/template for the title of the merge commit. The
+	// template is executed with a MergeTemplateData. Uses GitHub's default
+	// title if empty.
+	CommitTitle string `yaml:"commit-title,omitempty"`
+	// CommitBody is a text/template for the body of the merge commit. The
+	// template is executed with a MergeTemplateData. Uses GitHub's default
+	// body if empty.
+	CommitBody string `yaml:"commit-body,omitempty"`
+}
+
+// mergeMethods are the merge methods supported by GitHub.
+var mergeMethods = []string{"merge", "squash", "rebase"}
+
+// UnmarshalYAML validates the merge configuration once parsed so that an
+// invalid configuration is reported when it is loaded rather than when a PR
+// is about to be merged.
+func (mc *MergeConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain MergeConfig
+	if err := unmarshal((*plain)(mc)); err != nil {
+		return err
+	}
+	return mc.validate()
+}
+
+func (mc *MergeConfig) validate() error {
+	switch mc.Mode {
+	case MergeModeMerge, MergeModeAutoMerge:
+	default:
+		return fmt.Errorf("unknown merge mode %q, must be %q or %q", mc.Mode, MergeModeMerge, MergeModeAutoMerge)
+	}
+	if mc.Method != "" && !slices.Contains(mergeMethods, strings.ToLower(mc.Method)) {
+		return fmt.Errorf("unknown merge method %q, must be one of %q", mc.Method, mergeMethods)
+	}
+	for name, text := range map[string]string{"commit-title": mc.CommitTitle, "commit-body": mc.CommitBody} {
+		if _, err := template.New(name).Parse(text); err != nil {
+			return fmt.Errorf("unable to parse %s template: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// MergeTemplateData is the data available in the commit title and body
+// templates of MergeConfig.
+type MergeTemplateData struct {
+	Number  int
+	Title   string
+	Body    string
+	Author  string
+	HeadSHA string
+	BaseRef string
+}
+
+func executeMergeTemplate(name, text string, data MergeTemplateData) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return "", fmt.Errorf("unable to execute %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// isMergeabilityBlocked returns true if the Mergeability check for the given
+// head SHA and PR did not succeed. PRs without a Mergeability check are not
+// considered blocked.
+func (c *Client) isMergeabilityBlocked(ctx context.Context, owner, repoName string, prNumber int, headSHA string) (bool, error) {
+	cr, err := c.findMergeabilityCheckRun(ctx, owner, repoName, prNumber, headSHA)
+	if err != nil {
+		return false, err
+	}
+	return cr != nil && cr.GetConclusion() != "success", nil
+}
+
+// MergePR merges the given PR, or enables GitHub's native auto-merge, as
+// configured in 'cfg'. The merge is pinned to 'headSHA' so that commits pushed
+// after the PR was evaluated are never merged.
+func (c *Client) MergePR(cfg MergeConfig, owner, repoName string, prNumber int, headSHA string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pr, _, err := c.GHClient.PullRequests.Get(ctx, owner, repoName, prNumber)
+	if err != nil {
+		return err
+	}
+	// Every evaluation of a ready PR ends up here, there is nothing left to
+	// do once the PR is merged or set to be merged by GitHub.
+	switch {
+	case pr.GetMerged():
+		c.log.Info().Fields(map[string]interface{}{
+			"pr-number": prNumber,
+		}).Msg("Not merging PR because it is already merged")
+		return nil
+	case pr.GetAutoMerge() != nil:
+		c.log.Info().Fields(map[string]interface{}{
+			"pr-number": prNumber,
+		}).Msg("Not merging PR because auto-merge is already enabled")
+		return nil
+	case pr.GetHead().GetSHA() != headSHA:
+		c.log.Info().Fields(map[string]interface{}{
+			"pr-number":   prNumber,
+			"sha":         headSHA,
+			"current-sha": pr.GetHead().GetSHA(),
+		}).Msg("Not merging PR because its head changed")
+		return nil
+	}
+
+	blocked, err := c.isMergeabilityBlocked(ctx, owner, repoName, prNumber, headSHA)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		c.log.Info().Fields(map[string]interface{}{
+			"pr-number": prNumber,
+			"sha":       headSHA,
+		}).Msg("Not merging PR because it is blocked by the Mergeability check")
+		return nil
+	}
+
+	data := MergeTemplateData{
+		Number:  prNumber,
+		Title:   pr.GetTitle(),
+		Body:    pr.GetBody(),
+		Author:  pr.GetUser().GetLogin(),
+		HeadSHA: headSHA,
+		BaseRef: pr.GetBase().GetRef(),
+	}
+	title, err := executeMergeTemplate("commit-title", cfg.CommitTitle, data)
+	if err != nil {
+		return err
+	}
+	body, err := executeMergeTemplate("commit-body", cfg.CommitBody, data)
+	if err != nil {
+		return err
+	}
+	method := strings.ToLower(cfg.Method)
+	if method == "" {
+		method = "merge"
+	}
+
+	args := map[string]interface{}{
+		"mode":   cfg.Mode,
+		"method": method,
+		"sha":    headSHA,
+	}
+	err = c.mutate(ruleAutoMerge, "merge", owner, repoName, prNumber, args, func() error {
+		switch cfg.Mode {
+		case MergeModeMerge:
+			_, _, err := c.GHClient.PullRequests.Merge(ctx, owner, repoName, prNumber, body, &gh.PullRequestOptions{
+				CommitTitle: title,
+				SHA:         headSHA,
+				MergeMethod: method,
+			})
+			return err
+		case MergeModeAutoMerge:
+			mergeMethod := githubv4.PullRequestMergeMethod(strings.ToUpper(method))
+			input := githubv4.EnablePullRequestAutoMergeInput{
+				PullRequestID:   githubv4.ID(pr.GetNodeID()),
+				MergeMethod:     &mergeMethod,
+				ExpectedHeadOid: githubv4.NewGitObjectID(githubv4.GitObjectID(headSHA)),
+			}
+			if title != "" {
+				input.CommitHeadline = githubv4.NewString(githubv4.String(title))
+			}
+			if body != "" {
+				input.CommitBody = githubv4.NewString(githubv4.String(body))
+			}
+			var m struct {
+				EnablePullRequestAutoMerge struct {
+					PullRequest struct {
+						ID githubv4.ID
+					}
+				} `graphql:"enablePullRequestAutoMerge(input: $input)"`
+			}
+			return c.GHV4Client.Mutate(ctx, &m, input, nil)
+		default:
+			return fmt.Errorf("unknown merge mode %q", cfg.Mode)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("unable to merge PR %d with mode %q: %w", prNumber, cfg.Mode, err)
+	}
+
+	msg := "Merged PR"
+	if cfg.Mode == MergeModeAutoMerge {
+		msg = "Enabled auto-merge"
+	}
+	c.log.Info().Fields(map[string]interface{}{
+		"pr-number": prNumber,
+		"sha":       headSHA,
+		"mode":      cfg.Mode,
+		"method":    method,
+	}).Msg(msg)
+	return nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMergeConfig_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    MergeConfig
+		wantErr string
+	}{
+		{
+			name: "merge",
+			yaml: "mode: merge\nmethod: squash\ncommit-title: '{{ .Title }} (#{{ .Number }})'",
+			want: MergeConfig{Mode: "merge", Method: "squash", CommitTitle: "{{ .Title }} (#{{ .Number }})"},
+		},
+		{
+			name: "auto-merge with upper case method",
+			yaml: "mode: auto-merge\nmethod: REBASE",
+			want: MergeConfig{Mode: "auto-merge", Method: "REBASE"},
+		},
+		{
+			name:    "missing mode",
+			yaml:    "method: squash",
+			wantErr: `unknown merge mode ""`,
+		},
+		{
+			name:    "unknown mode",
+			yaml:    "mode: fast-forward",
+			wantErr: `unknown merge mode "fast-forward"`,
+		},
+		{
+			name:    "unknown method",
+			yaml:    "mode: merge\nmethod: octopus",
+			wantErr: `unknown merge method "octopus"`,
+		},
+		{
+			name:    "invalid template",
+			yaml:    "mode: merge\ncommit-body: '{{ .Body'",
+			wantErr: "unable to parse commit-body template",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got MergeConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &got)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// Invalid merge configurations fail the whole configuration.
+	var cfg PRBlockerConfig
+	err := yaml.Unmarshal([]byte("auto-merge:\n  branches:\n    - branch: v*\n      merge:\n        mode: now"), &cfg)
+	assert.ErrorContains(t, err, `unknown merge mode "now"`)
+}
+
+func Test_executeMergeTemplate(t *testing.T) {
+	data := MergeTemplateData{
+		Number:  42,
+		Title:   "Fix the bug",
+		Body:    "The bug is fixed.",
+		Author:  "alice",
+		HeadSHA: "abc",
+		BaseRef: "main",
+	}
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "empty",
+			text: "",
+			want: "",
+		},
+		{
+			name: "all fields",
+			text: "{{ .Title }} (#{{ .Number }}) by {{ .Author }} at {{ .HeadSHA }} into {{ .BaseRef }}: {{ .Body }}",
+			want: "Fix the bug (#42) by alice at abc into main: The bug is fixed.",
+		},
+		{
+			name:    "unknown field",
+			text:    "{{ .Reviewers }}",
+			wantErr: "unable to execute commit-title template",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := executeMergeTemplate("commit-title", tt.text, data)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_MergePR(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  MergeConfig
+		// pushed is pushed to the PR after it was evaluated at "c1".
+		pushed       string
+		mergeability string
+		want         *githubtest.Merge
+		wantErr      string
+	}{
+		{
+			name: "merge with templates",
+			cfg: MergeConfig{
+				Mode:        MergeModeMerge,
+				Method:      "Squash",
+				CommitTitle: "{{ .Title }} (#{{ .Number }})",
+				CommitBody:  "{{ .Body }}",
+			},
+			want: &githubtest.Merge{Method: "squash", Title: "Fix the bug (#1)", Body: "The bug is fixed.", SHA: "c1"},
+		},
+		{
+			name: "merge with GitHub's defaults",
+			cfg:  MergeConfig{Mode: MergeModeMerge},
+			want: &githubtest.Merge{Method: "merge", SHA: "c1"},
+		},
+		{
+			name: "auto-merge",
+			cfg: MergeConfig{
+				Mode:        MergeModeAutoMerge,
+				Method:      "rebase",
+				CommitTitle: "{{ .Title }}",
+			},
+			want: &githubtest.Merge{AutoMerge: true, Method: "rebase", Title: "Fix the bug", SHA: "c1"},
+		},
+		{
+			name:   "head changed",
+			cfg:    MergeConfig{Mode: MergeModeMerge},
+			pushed: "c2",
+			want:   nil,
+		},
+		{
+			name:         "blocked by the Mergeability check",
+			cfg:          MergeConfig{Mode: MergeModeAutoMerge},
+			mergeability: "failure",
+			want:         nil,
+		},
+		{
+			name:         "not blocked by the Mergeability check",
+			cfg:          MergeConfig{Mode: MergeModeMerge},
+			mergeability: "success",
+			want:         &githubtest.Merge{Method: "merge", SHA: "c1"},
+		},
+		{
+			name:    "unknown mode",
+			cfg:     MergeConfig{Mode: "fast-forward"},
+			wantErr: `unknown merge mode "fast-forward"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			commits := []githubtest.Commit{{SHA: "c1", Message: "Fix the bug"}}
+			if tt.pushed != "" {
+				commits = append(commits, githubtest.Commit{SHA: tt.pushed, Message: "Fix it again"})
+			}
+			srv.AddPR("cilium", "cilium", githubtest.PR{
+				Title:   "Fix the bug",
+				Body:    "The bug is fixed.",
+				Author:  "alice",
+				Commits: commits,
+			})
+			if tt.mergeability != "" {
+				srv.AddCheckRun("cilium", "cilium", "c1", githubtest.AppID, mergeabilityCheckName, "completed", tt.mergeability)
+			}
+
+			err := c.MergePR(tt.cfg, "cilium", "cilium", 1, "c1")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, srv.Merge("cilium", "cilium", 1))
+		})
+	}
+}
+
+func TestClient_MergePR_again(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  MergeConfig
+		// mutation is the request that merges the PR.
+		mutation string
+	}{
+		{
+			name:     "merged",
+			cfg:      MergeConfig{Mode: MergeModeMerge},
+			mutation: "PUT /repos/cilium/cilium/pulls/1/merge",
+		},
+		{
+			name:     "auto-merge enabled",
+			cfg:      MergeConfig{Mode: MergeModeAutoMerge},
+			mutation: "POST /graphql",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			srv.AddPR("cilium", "cilium", githubtest.PR{
+				Author:  "alice",
+				Commits: []githubtest.Commit{{SHA: "c1", Message: "Fix the bug"}},
+			})
+
+			// Ready PRs are evaluated again until merged, which must
+			// not merge them again.
+			assert.NoError(t, c.MergePR(tt.cfg, "cilium", "cilium", 1, "c1"))
+			assert.NoError(t, c.MergePR(tt.cfg, "cilium", "cilium", 1, "c1"))
+			var mutations int
+			for _, req := range srv.Requests() {
+				if req == tt.mutation {
+					mutations++
+				}
+			}
+			assert.Equal(t, 1, mutations)
+		})
+	}
+}
@@ -17,12 +17,31 @@ package github
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"maps"
+	"strings"
 	"time"
 
 	gh "github.com/google/go-github/v84/github"
 )
 
+// mergeabilityCheckName is the name of the check run that reports if a PR is
+// blocked from being merged.
+const mergeabilityCheckName = "Mergeability"
+
+// reevaluateActionID is the identifier of the "Re-evaluate" button of our
+// check runs.
+const reevaluateActionID = "reevaluate"
+
+// reevaluateActions are the actions shown in our check runs, which allow
+// contributors to re-evaluate a PR, e.g., after a missed webhook.
+var reevaluateActions = []*gh.CheckRunAction{
+	{
+		Label:       "Re-evaluate",
+		Description: "Evaluate the PR again",
+		Identifier:  reevaluateActionID,
+	},
+}
+
 type PRLabelConfig struct {
 	// RegexLabel contains the regex that will be used to find for labels.
 	RegexLabel string `yaml:"regex-label,omitempty"`
@@ -43,93 +62,143 @@ type BlockPRWith struct {
 	LabelsSet []PRLabelConfig `yaml:"labels-set,omitempty"`
 }
 
-// BlockPRWith returns true if the PR needs to be blocked based on the logic
-// stored under config.BlockPRWith.
-func (c *Client) BlockPRWith(blockPRConfig BlockPRWith, owner, repoName string, prNumber int, prLabels PRLabels) (bool, []string, error) {
-	var (
-		blockPR      bool
-		cancels      []context.CancelFunc
-		blockReasons []string
-	)
-	defer func() {
-		for _, cancel := range cancels {
-			cancel()
-		}
-	}()
+// labelsUnsetStep is the change that a labels-unset entry makes to the labels
+// of a PR.
+type labelsUnsetStep struct {
+	cfg PRLabelConfig
+	// found is true if a label of the PR matches cfg.RegexLabel, in which
+	// case cfg.SetLabels are removed from the PR. Otherwise they are added.
+	found bool
+	// comment is true if cfg.Helper needs to be commented in the PR, i.e., if
+	// cfg.SetLabels were not all set yet.
+	comment bool
+}
+
+// blockPRWithResult is the outcome of evaluating BlockPRWith for a PR.
+type blockPRWithResult struct {
+	blockPR      bool
+	blockReasons []string
+	// steps are the changes to make to the labels of the PR, in order.
+	steps []labelsUnsetStep
+}
+
+// evaluate returns whether the PR with the given labels needs to be blocked
+// and the label changes that come with it, without changing the PR nor
+// prLabels. The labels-set entries are matched against the labels of the PR
+// once those changes are made.
+func (b BlockPRWith) evaluate(prLabels PRLabels) (blockPRWithResult, error) {
+	var res blockPRWithResult
+	labels := maps.Clone(prLabels)
+	if labels == nil {
+		labels = PRLabels{}
+	}
 
 	// Check which labels are not set in the PR.
-	for _, lblsUnset := range blockPRConfig.LabelsUnset {
-		var found bool
-		for prLbl := range prLabels {
-			matched, err := regexp.MatchString(lblsUnset.RegexLabel, prLbl)
-			if err != nil {
-				return false, nil, err
-			}
-			if matched {
-				found = true
-				break
-			}
+	for _, lblsUnset := range b.LabelsUnset {
+		found, err := labels.matchAny(lblsUnset.RegexLabel)
+		if err != nil {
+			return blockPRWithResult{}, err
 		}
+		step := labelsUnsetStep{cfg: lblsUnset, found: found}
 		if found {
 			// If the labels are set then remove all previously set labels that
 			// are blocking the mergeability of this PR.
 			for _, lbl := range lblsUnset.SetLabels {
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				cancels = append(cancels, cancel)
-				_, err := c.GHClient.Issues.RemoveLabelForIssue(ctx, owner, repoName, prNumber, lbl)
-				if err != nil && !IsNotFound(err) {
-					return false, nil, err
-				}
-				delete(prLabels, lbl)
+				delete(labels, lbl)
 			}
 		} else {
-			blockPR = true
+			res.blockPR = true
 			// If they are not leave helper message and add labels to help
 			// users avoiding PR from being merged.
 			// Don't re-print helper messages if we already have setup the
 			// labels in the past.
-			if lblsUnset.Helper != "" && !subslice(lblsUnset.SetLabels, prLabels) {
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				cancels = append(cancels, cancel)
-				_, _, err := c.GHClient.Issues.CreateComment(ctx, owner, repoName, prNumber, &gh.IssueComment{
-					Body: &lblsUnset.Helper,
-				})
-				if err != nil {
-					return false, nil, err
-				}
+			step.comment = lblsUnset.Helper != "" && !subslice(lblsUnset.SetLabels, labels)
+			for _, lbl := range lblsUnset.SetLabels {
+				labels[lbl] = struct{}{}
+			}
+		}
+		res.steps = append(res.steps, step)
+	}
+
+	// Set the PR to be blocked if any of the labels provided by the regex is
+	// currently set in the PR
+	for _, lblsSet := range b.LabelsSet {
+		found, err := labels.matchAny(lblsSet.RegexLabel)
+		if err != nil {
+			return blockPRWithResult{}, err
+		}
+		if found {
+			res.blockPR = true
+			if lblsSet.Helper != "" {
+				res.blockReasons = append(res.blockReasons, lblsSet.Helper)
 			}
-			if len(lblsUnset.SetLabels) != 0 {
+		}
+	}
+	return res, nil
+}
+
+// BlockPRWith returns true if the PR needs to be blocked based on the logic
+// stored under config.BlockPRWith.
+func (c *Client) BlockPRWith(blockPRConfig BlockPRWith, owner, repoName string, prNumber int, prLabels PRLabels) (bool, []string, error) {
+	var (
+		cancels []context.CancelFunc
+	)
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	res, err := blockPRConfig.evaluate(prLabels)
+	if err != nil {
+		return false, nil, err
+	}
+	for _, step := range res.steps {
+		if step.found {
+			for _, lbl := range step.cfg.SetLabels {
 				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 				cancels = append(cancels, cancel)
-				_, _, err := c.GHClient.Issues.AddLabelsToIssue(ctx, owner, repoName, prNumber, lblsUnset.SetLabels)
-				if err != nil {
+				err := c.removeLabel(ctx, ruleBlockPRWith, owner, repoName, prNumber, lbl)
+				if err != nil && !IsNotFound(err) {
 					return false, nil, err
 				}
-				for _, lbl := range lblsUnset.SetLabels {
-					prLabels[lbl] = struct{}{}
-				}
+				delete(prLabels, lbl)
 			}
+			continue
 		}
-	}
-
-	// Set the PR to be blocked if any of the labels provided by the regex is
-	// currently set in the PR
-	for _, lblsSet := range blockPRConfig.LabelsSet {
-		for prLbl := range prLabels {
-			matched, err := regexp.MatchString(lblsSet.RegexLabel, prLbl)
+		if step.comment {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			cancels = append(cancels, cancel)
+			err := c.createComment(ctx, ruleBlockPRWith, owner, repoName, prNumber, step.cfg.Helper)
 			if err != nil {
 				return false, nil, err
 			}
-			if matched {
-				blockPR = true
-				if lblsSet.Helper != "" {
-					blockReasons = append(blockReasons, lblsSet.Helper)
-				}
-				break
+		}
+		if len(step.cfg.SetLabels) != 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			cancels = append(cancels, cancel)
+			err := c.addLabels(ctx, ruleBlockPRWith, owner, repoName, prNumber, step.cfg.SetLabels)
+			if err != nil {
+				return false, nil, err
+			}
+			for _, lbl := range step.cfg.SetLabels {
+				prLabels[lbl] = struct{}{}
 			}
 		}
 	}
-	return blockPR, blockReasons, nil
+	return res.blockPR, res.blockReasons, nil
+}
+
+// IsBlocked returns true if the PR with the given labels needs to be blocked
+// based on the logic stored under config.BlockPRWith, along with the same
+// reasons as Client.BlockPRWith. Unlike Client.BlockPRWith, it never changes
+// the PR.
+func (b BlockPRWith) IsBlocked(prLabels PRLabels) (bool, []string, error) {
+	res, err := b.evaluate(prLabels)
+	if err != nil {
+		return false, nil, err
+	}
+	return res.blockPR, res.blockReasons, nil
 }
 
 // UpdateMergeabilityCheck sets the mergeability checker with "Success" or
@@ -143,13 +212,10 @@ func (c *Client) UpdateMergeabilityCheck(
 	blockReasons []string,
 ) error {
 
-	const checkerName = "Mergeability"
+	const checkerName = mergeabilityCheckName
 
 	var (
-		conclusion string
-		title      string
-		summary    string
-		cancels    []context.CancelFunc
+		cancels []context.CancelFunc
 	)
 	defer func() {
 		for _, cancel := range cancels {
@@ -157,25 +223,15 @@ func (c *Client) UpdateMergeabilityCheck(
 		}
 	}()
 
-	if blockPR {
-		conclusion = "failure"
-		title = "Not mergeable!"
-		summary = fmt.Sprintf("Blocking PR since it's not in a mergeable state due %s", blockReasons)
-	} else {
-		conclusion = "success"
-		title = "Mergeable!"
-		summary = "Everything is set up correctly!"
-	}
+	conclusion, title, summary := mergeabilityOutput(blockPR, blockReasons)
+	c.metrics.mergeability(blockPR)
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	cancels = append(cancels, cancel)
 	nextPage := 0
 	for {
-		lc, resp, err := c.GHClient.Checks.ListCheckRunsForRef(ctx, owner, repoName, head.GetSHA(), &gh.ListCheckRunsOptions{
-			CheckName: func() *string { ; return new(checkerName) }(),
-			ListOptions: gh.ListOptions{
-				Page: nextPage,
-			},
-		})
+		opts := c.ownCheckRunsOptions(checkerName)
+		opts.Page = nextPage
+		lc, resp, err := c.GHClient.Checks.ListCheckRunsForRef(ctx, owner, repoName, head.GetSHA(), opts)
 		switch {
 		case err != nil && !IsNotFound(err):
 			return err
@@ -183,9 +239,17 @@ func (c *Client) UpdateMergeabilityCheck(
 			for _, cr := range lc.CheckRuns {
 				for _, pr := range cr.PullRequests {
 					if pr.GetNumber() == prNumber {
+						// Keep the sections set by SetMergeabilitySection.
+						summary := summary + mergeabilitySections(cr.GetOutput().GetSummary())
+						if cr.GetStatus() == "completed" &&
+							cr.GetConclusion() == conclusion &&
+							cr.GetOutput().GetTitle() == title &&
+							cr.GetOutput().GetSummary() == summary {
+							return nil
+						}
 						ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 						cancels = append(cancels, cancel)
-						_, _, err := c.GHClient.Checks.UpdateCheckRun(ctx, owner, repoName, cr.GetID(), gh.UpdateCheckRunOptions{
+						err := c.updateCheckRun(ctx, ruleBlockPRWith, owner, repoName, prNumber, cr.GetID(), gh.UpdateCheckRunOptions{
 							Name:       checkerName,
 							ExternalID: head.SHA,
 							Status:     func() *string { ; return new("completed") }(),
@@ -197,6 +261,7 @@ func (c *Client) UpdateMergeabilityCheck(
 								Title:   &title,
 								Summary: &summary,
 							},
+							Actions: reevaluateActions,
 						})
 						c.log.Info().Fields(map[string]interface{}{
 							"pr-number": prNumber,
@@ -223,7 +288,7 @@ func (c *Client) UpdateMergeabilityCheck(
 		case IsNotFound(err):
 			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			cancels = append(cancels, cancel)
-			_, _, err := c.GHClient.Checks.CreateCheckRun(ctx, owner, repoName, gh.CreateCheckRunOptions{
+			err := c.createCheckRun(ctx, ruleBlockPRWith, owner, repoName, prNumber, gh.CreateCheckRunOptions{
 				Name:       checkerName,
 				HeadSHA:    head.GetSHA(),
 				ExternalID: head.SHA,
@@ -236,10 +301,114 @@ func (c *Client) UpdateMergeabilityCheck(
 					Title:   &title,
 					Summary: &summary,
 				},
-				Actions: nil,
+				Actions: reevaluateActions,
 			})
 			return err
 		}
 
 	}
 }
+
+// mergeabilityOutput returns the conclusion, title and summary of the
+// Mergeability check.
+func mergeabilityOutput(blockPR bool, blockReasons []string) (conclusion, title, summary string) {
+	if blockPR {
+		return "failure", "Not mergeable!", fmt.Sprintf("Blocking PR since it's not in a mergeable state due %s", blockReasons)
+	}
+	return "success", "Mergeable!", "Everything is set up correctly!"
+}
+
+// mergeabilitySectionHeader starts each of the sections appended to the
+// summary of the Mergeability check by SetMergeabilitySection.
+const mergeabilitySectionHeader = "\n\n### "
+
+// mergeabilitySections returns all sections of the given summary.
+func mergeabilitySections(summary string) string {
+	if i := strings.Index(summary, mergeabilitySectionHeader); i != -1 {
+		return summary[i:]
+	}
+	return ""
+}
+
+// setMergeabilitySection replaces the section 'name' of the given summary
+// with a list of 'items'. The section is removed if 'items' is empty.
+func setMergeabilitySection(summary, name string, items []string) string {
+	sections := mergeabilitySections(summary)
+
+	var b strings.Builder
+	b.WriteString(strings.TrimSuffix(summary, sections))
+	for _, section := range strings.Split(sections, mergeabilitySectionHeader) {
+		title, _, _ := strings.Cut(section, "\n")
+		if section == "" || title == name {
+			continue
+		}
+		b.WriteString(mergeabilitySectionHeader + section)
+	}
+	if len(items) != 0 {
+		b.WriteString(mergeabilitySectionHeader + name + "\n")
+		for _, item := range items {
+			b.WriteString("\n- " + item)
+		}
+	}
+	return b.String()
+}
+
+// findMergeabilityCheckRun returns the Mergeability check run of the given PR
+// and head SHA, or nil if it does not exist.
+func (c *Client) findMergeabilityCheckRun(ctx context.Context, owner, repoName string, prNumber int, headSHA string) (*gh.CheckRun, error) {
+	nextPage := 0
+	for {
+		opts := c.ownCheckRunsOptions(mergeabilityCheckName)
+		opts.Page = nextPage
+		lc, resp, err := c.GHClient.Checks.ListCheckRunsForRef(ctx, owner, repoName, headSHA, opts)
+		switch {
+		case IsNotFound(err):
+			return nil, nil
+		case err != nil:
+			return nil, err
+		}
+		for _, cr := range lc.CheckRuns {
+			for _, pr := range cr.PullRequests {
+				if pr.GetNumber() == prNumber {
+					return cr, nil
+				}
+			}
+		}
+		nextPage = resp.NextPage
+		if nextPage == 0 {
+			return nil, nil
+		}
+	}
+}
+
+// SetMergeabilitySection sets the section 'name' of the Mergeability check
+// summary to the list of 'items', without changing the conclusion of the
+// check. The section is removed if 'items' is empty. It is a no-op if the PR
+// does not have a Mergeability check.
+func (c *Client) SetMergeabilitySection(owner, repoName string, prNumber int, headSHA, name string, items []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cr, err := c.findMergeabilityCheckRun(ctx, owner, repoName, prNumber, headSHA)
+	if err != nil || cr == nil {
+		return err
+	}
+
+	summary := setMergeabilitySection(cr.GetOutput().GetSummary(), name, items)
+	if summary == cr.GetOutput().GetSummary() {
+		return nil
+	}
+	// The sections are only set by the auto-merge evaluation.
+	err = c.updateCheckRun(ctx, ruleAutoMerge, owner, repoName, prNumber, cr.GetID(), gh.UpdateCheckRunOptions{
+		Name: mergeabilityCheckName,
+		Output: &gh.CheckRunOutput{
+			Title:   cr.GetOutput().Title,
+			Summary: &summary,
+		},
+	})
+	c.log.Info().Fields(map[string]interface{}{
+		"pr-number": prNumber,
+		"section":   name,
+	}).Err(err).Msg("Updating Mergeability section for PR")
+	return err
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	gh "github.com/google/go-github/v84/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_setMergeabilitySection(t *testing.T) {
+	summary := "Everything is set up correctly!"
+
+	summary = setMergeabilitySection(summary, "Code owners without approval", []string{"@cilium/sig-policy"})
+	assert.Equal(t, "Everything is set up correctly!\n\n"+
+		"### Code owners without approval\n\n"+
+		"- @cilium/sig-policy", summary)
+
+	summary = setMergeabilitySection(summary, "Unresolved review threads", []string{"https://a", "https://b"})
+	summary = setMergeabilitySection(summary, "Code owners without approval", []string{"@cilium/docs"})
+	assert.Equal(t, "Everything is set up correctly!\n\n"+
+		"### Unresolved review threads\n\n"+
+		"- https://a\n"+
+		"- https://b\n\n"+
+		"### Code owners without approval\n\n"+
+		"- @cilium/docs", summary)
+
+	summary = setMergeabilitySection(summary, "Unresolved review threads", nil)
+	summary = setMergeabilitySection(summary, "Code owners without approval", nil)
+	assert.Equal(t, "Everything is set up correctly!", summary)
+}
+
+func TestBlockPRWith_IsBlocked(t *testing.T) {
+	cfg := BlockPRWith{
+		LabelsUnset: []PRLabelConfig{
+			{
+				RegexLabel: "release-note/.*",
+				Helper:     "Please set a release note label.",
+				SetLabels:  []string{"dont-merge/needs-release-note-label"},
+			},
+		},
+		LabelsSet: []PRLabelConfig{
+			{RegexLabel: "dont-merge/.*", Helper: "Blocking mergeability of PR with 'dont-merge/.*' labels"},
+		},
+	}
+	tests := []struct {
+		name        string
+		labels      []string
+		wantBlockPR bool
+		wantReasons []string
+	}{
+		{
+			name:   "mergeable",
+			labels: []string{"release-note/bug"},
+		},
+		{
+			name:        "label set",
+			labels:      []string{"release-note/bug", "dont-merge/preview-only"},
+			wantBlockPR: true,
+			wantReasons: []string{"Blocking mergeability of PR with 'dont-merge/.*' labels"},
+		},
+		{
+			name:        "label unset",
+			wantBlockPR: true,
+			wantReasons: []string{"Blocking mergeability of PR with 'dont-merge/.*' labels"},
+		},
+		{
+			name:        "label unset and its labels already set",
+			labels:      []string{"dont-merge/needs-release-note-label"},
+			wantBlockPR: true,
+			wantReasons: []string{"Blocking mergeability of PR with 'dont-merge/.*' labels"},
+		},
+		{
+			name:   "label no longer unset",
+			labels: []string{"release-note/bug", "dont-merge/needs-release-note-label"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prLabels := PRLabels{}
+			for _, lbl := range tt.labels {
+				prLabels[lbl] = struct{}{}
+			}
+
+			blockPR, reasons, err := cfg.IsBlocked(prLabels)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBlockPR, blockPR)
+			assert.Equal(t, tt.wantReasons, reasons)
+			assert.Len(t, prLabels, len(tt.labels), "labels must not change")
+
+			// The PR is blocked for the same reasons once its labels are
+			// changed.
+			srv, c := newFakeClient(t)
+			srv.AddPR("cilium", "cilium", githubtest.PR{Labels: tt.labels})
+			blockPR, reasons, err = c.BlockPRWith(cfg, "cilium", "cilium", 1, prLabels)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBlockPR, blockPR)
+			assert.Equal(t, tt.wantReasons, reasons)
+		})
+	}
+}
+
+func TestClient_HandlePullRequestEvent_blockPRWith(t *testing.T) {
+	srv, c := newFakeClient(t)
+	srv.AddPR("cilium", "cilium", githubtest.PR{
+		Number:  1,
+		Commits: []githubtest.Commit{{SHA: "abc"}},
+	})
+	cfg := PRBlockerConfig{
+		BlockPRWith: BlockPRWith{
+			LabelsUnset: []PRLabelConfig{
+				{
+					RegexLabel: "^release-note/",
+					Helper:     "Please set a release note label.",
+					SetLabels:  []string{"dont-merge/needs-release-note-label"},
+				},
+			},
+		},
+	}
+
+	err := c.HandlePullRequestEvent(cfg, &gh.PullRequestEvent{
+		Action:      new("opened"),
+		PullRequest: srv.PullRequest("cilium", "cilium", 1),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dont-merge/needs-release-note-label"}, srv.Labels("cilium", "cilium", 1))
+	assert.Equal(t, []string{"Please set a release note label."}, srv.Comments("cilium", "cilium", 1))
+	assert.Equal(t, "failure", srv.CheckRun("cilium", "cilium", "abc", mergeabilityCheckName).GetConclusion())
+
+	_, _, err = srv.Client().Issues.AddLabelsToIssue(context.Background(), "cilium", "cilium", 1, []string{"release-note/bug"})
+	assert.NoError(t, err)
+	err = c.HandlePullRequestEvent(cfg, &gh.PullRequestEvent{
+		Action:      new("labeled"),
+		Label:       &gh.Label{Name: new("release-note/bug")},
+		PullRequest: srv.PullRequest("cilium", "cilium", 1),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"release-note/bug"}, srv.Labels("cilium", "cilium", 1))
+	assert.Len(t, srv.Comments("cilium", "cilium", 1), 1)
+	assert.Equal(t, "success", srv.CheckRun("cilium", "cilium", "abc", mergeabilityCheckName).GetConclusion())
+}
+
+func TestClient_UpdateMergeabilityCheck_unchanged(t *testing.T) {
+	srv, c := newFakeClient(t)
+	pr := srv.AddPR("cilium", "cilium", githubtest.PR{
+		Number:  1,
+		Commits: []githubtest.Commit{{SHA: "abc"}},
+	})
+	patches := func() int {
+		var n int
+		for _, req := range srv.Requests() {
+			if strings.HasPrefix(req, "PATCH ") {
+				n++
+			}
+		}
+		return n
+	}
+
+	err := c.UpdateMergeabilityCheck("cilium", "cilium", 1, pr.GetHead(), true, []string{"reason"})
+	assert.NoError(t, err)
+	err = c.SetMergeabilitySection("cilium", "cilium", 1, "abc", "Required checks not passed", []string{"ci/build: pending"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, patches())
+
+	// Neither the conclusion nor the sections changed.
+	err = c.UpdateMergeabilityCheck("cilium", "cilium", 1, pr.GetHead(), true, []string{"reason"})
+	assert.NoError(t, err)
+	err = c.SetMergeabilitySection("cilium", "cilium", 1, "abc", "Required checks not passed", []string{"ci/build: pending"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, patches())
+
+	err = c.UpdateMergeabilityCheck("cilium", "cilium", 1, pr.GetHead(), false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, patches())
+	cr := srv.CheckRun("cilium", "cilium", "abc", mergeabilityCheckName)
+	assert.Equal(t, "success", cr.GetConclusion())
+	assert.Contains(t, cr.GetOutput().GetSummary(), "ci/build: pending")
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// mergeGroupRefRegexp extracts the number of the last PR of a merge group
+// from its head ref, e.g., "refs/heads/gh-readonly-queue/main/pr-123-<sha>".
+var mergeGroupRefRegexp = regexp.MustCompile(`/pr-([0-9]+)-[0-9a-f]+$`)
+
+// getMergeGroupPRs returns the numbers of the PRs included in the merge group
+// with the given head SHA. Since each merge group is built on top of the
+// entries ahead of it in the merge queue, those are all the queue entries up
+// to, and including, the one with the given head SHA.
+func (c *Client) getMergeGroupPRs(ctx context.Context, owner, repoName string, mg *gh.MergeGroup) ([]int, error) {
+	var q struct {
+		Repository struct {
+			MergeQueue struct {
+				Entries struct {
+					Nodes []struct {
+						HeadCommit struct {
+							Oid githubv4.GitObjectID
+						}
+						PullRequest struct {
+							Number githubv4.Int
+						}
+					}
+				} `graphql:"entries(first: 100)"`
+			} `graphql:"mergeQueue(branch: $branch)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	err := c.GHV4Client.Query(ctx, &q, map[string]interface{}{
+		"owner":  githubv4.String(owner),
+		"name":   githubv4.String(repoName),
+		"branch": githubv4.String(strings.TrimPrefix(mg.GetBaseRef(), "refs/heads/")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get merge queue of %q: %w", mg.GetBaseRef(), err)
+	}
+
+	var prNumbers []int
+	for _, entry := range q.Repository.MergeQueue.Entries.Nodes {
+		prNumbers = append(prNumbers, int(entry.PullRequest.Number))
+		if string(entry.HeadCommit.Oid) == mg.GetHeadSHA() {
+			return prNumbers, nil
+		}
+	}
+
+	// The merge group is no longer in the queue, or the queue changed in the
+	// meantime, so fall back to the PR referenced by the head ref.
+	m := mergeGroupRefRegexp.FindStringSubmatch(mg.GetHeadRef())
+	if m == nil {
+		return nil, fmt.Errorf("unable to find PRs of merge group %q", mg.GetHeadRef())
+	}
+	prNumber, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil, err
+	}
+	return []int{prNumber}, nil
+}
+
+// HandleMergeGroupEvent creates the Mergeability check in the head SHA of a
+// merge group. The merge group is blocked if any of its PRs is blocked.
+func (c *Client) HandleMergeGroupEvent(cfg PRBlockerConfig, e *gh.MergeGroupEvent) error {
+	owner := e.GetRepo().GetOwner().GetLogin()
+	repoName := e.GetRepo().GetName()
+	mg := e.GetMergeGroup()
+	c.log.Info().Fields(map[string]interface{}{
+		"action":   e.GetAction(),
+		"head-ref": mg.GetHeadRef(),
+		"sha":      mg.GetHeadSHA(),
+	}).Msg("Action triggered from merge group")
+
+	if e.GetAction() != "checks_requested" {
+		return nil
+	}
+	if len(cfg.BlockPRWith.LabelsUnset) == 0 && len(cfg.BlockPRWith.LabelsSet) == 0 && len(cfg.MoveToProjectsForLabelsXORed) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	prNumbers, err := c.getMergeGroupPRs(ctx, owner, repoName, mg)
+	if err != nil {
+		return err
+	}
+
+	var (
+		blockGroup   bool
+		blockReasons []string
+	)
+	for _, prNumber := range prNumbers {
+		pr, _, err := c.GHClient.PullRequests.Get(ctx, owner, repoName, prNumber)
+		if err != nil {
+			return err
+		}
+		prLabels := parseGHLabels(pr.Labels)
+		blockPR, reasons, err := cfg.BlockPRWith.IsBlocked(prLabels)
+		if err != nil {
+			return err
+		}
+		reasons = append(reasons, cfg.MoveToProjectsForLabelsXORed.BlockReasons(prLabels)...)
+		if blockPR || len(reasons) != 0 {
+			blockGroup = true
+			for _, reason := range reasons {
+				blockReasons = append(blockReasons, fmt.Sprintf("#%d: %s", prNumber, reason))
+			}
+		}
+	}
+
+	conclusion, title, summary := mergeabilityOutput(blockGroup, blockReasons)
+	err = c.createCheckRun(ctx, ruleBlockPRWith, owner, repoName, 0, gh.CreateCheckRunOptions{
+		Name:        mergeabilityCheckName,
+		HeadSHA:     mg.GetHeadSHA(),
+		ExternalID:  mg.HeadSHA,
+		Status:      new("completed"),
+		Conclusion:  &conclusion,
+		CompletedAt: &gh.Timestamp{Time: time.Now()},
+		Output: &gh.CheckRunOutput{
+			Title:   &title,
+			Summary: &summary,
+		},
+	})
+	c.log.Info().Fields(map[string]interface{}{
+		"head-ref":   mg.GetHeadRef(),
+		"pr-numbers": prNumbers,
+		"blockPR":    blockGroup,
+	}).Err(err).Msg("Creating Mergeability for merge group")
+	return err
+}
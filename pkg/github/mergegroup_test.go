@@ -0,0 +1,114 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	gh "github.com/google/go-github/v84/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_getMergeGroupPRs(t *testing.T) {
+	queue := []githubtest.MergeQueueEntry{
+		{HeadSHA: "g1", Number: 10},
+		{HeadSHA: "g2", Number: 11},
+		{HeadSHA: "g3", Number: 12},
+	}
+	tests := []struct {
+		name    string
+		headSHA string
+		headRef string
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:    "first entry",
+			headSHA: "g1",
+			headRef: "refs/heads/gh-readonly-queue/main/pr-10-0123abc",
+			want:    []int{10},
+		},
+		{
+			name:    "entries ahead are included",
+			headSHA: "g3",
+			headRef: "refs/heads/gh-readonly-queue/main/pr-12-0123abc",
+			want:    []int{10, 11, 12},
+		},
+		{
+			name:    "not in the queue anymore",
+			headSHA: "g4",
+			headRef: "refs/heads/gh-readonly-queue/main/pr-13-0123abc",
+			want:    []int{13},
+		},
+		{
+			name:    "unknown head ref",
+			headSHA: "g4",
+			headRef: "refs/heads/main",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			srv.SetMergeQueue("cilium", "cilium", "main", queue...)
+
+			got, err := c.getMergeGroupPRs(context.Background(), "cilium", "cilium", &gh.MergeGroup{
+				HeadSHA: new(tt.headSHA),
+				HeadRef: new(tt.headRef),
+				BaseRef: new("refs/heads/main"),
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_HandleMergeGroupEvent(t *testing.T) {
+	srv, c := newFakeClient(t)
+	srv.AddPR("cilium", "cilium", githubtest.PR{Number: 10, Labels: []string{"release-note/bug"}})
+	srv.AddPR("cilium", "cilium", githubtest.PR{Number: 11, Labels: []string{"release-note/bug", "dont-merge/wait"}})
+	srv.SetMergeQueue("cilium", "cilium", "main",
+		githubtest.MergeQueueEntry{HeadSHA: "g1", Number: 10},
+		githubtest.MergeQueueEntry{HeadSHA: "g2", Number: 11},
+	)
+	cfg := PRBlockerConfig{
+		BlockPRWith: BlockPRWith{
+			LabelsSet: []PRLabelConfig{{RegexLabel: "dont-merge/.*", Helper: "dont-merge label set"}},
+		},
+	}
+
+	for _, sha := range []string{"g1", "g2"} {
+		err := c.HandleMergeGroupEvent(cfg, &gh.MergeGroupEvent{
+			Action: new("checks_requested"),
+			MergeGroup: &gh.MergeGroup{
+				HeadSHA: new(sha),
+				HeadRef: new("refs/heads/gh-readonly-queue/main/pr-1-0123abc"),
+				BaseRef: new("refs/heads/main"),
+			},
+			Repo: &gh.Repository{Name: new("cilium"), Owner: &gh.User{Login: new("cilium")}},
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "success", srv.CheckRun("cilium", "cilium", "g1", mergeabilityCheckName).GetConclusion())
+	cr := srv.CheckRun("cilium", "cilium", "g2", mergeabilityCheckName)
+	assert.Equal(t, "failure", cr.GetConclusion())
+	assert.Contains(t, cr.GetOutput().GetSummary(), "#11: dont-merge label set")
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// Outcomes of the triage of a flaky test failure.
+const (
+	flakeKnownIssue = "known-issue"
+	flakeNewIssue   = "new-issue"
+	flakeNotFound   = "not-found"
+)
+
+// Metrics records the decisions of the clients in a metrics registry. A nil
+// *Metrics records nothing.
+type Metrics struct {
+	registry metrics.Registry
+}
+
+// NewMetrics returns a Metrics that registers its metrics in the given
+// registry.
+func NewMetrics(registry metrics.Registry) *Metrics {
+	return &Metrics{registry: registry}
+}
+
+// SetMetrics sets the metrics in which the client records its decisions.
+func (c *Client) SetMetrics(m *Metrics) {
+	c.metrics = m
+}
+
+// Metrics returns the metrics in which the client records its decisions, if
+// any.
+func (c *Client) Metrics() *Metrics {
+	return c.metrics
+}
+
+func (m *Metrics) counter(name string, tags ...interface{}) metrics.Counter {
+	return metrics.GetOrRegisterCounter(fmt.Sprintf(name, tags...), m.registry)
+}
+
+func (m *Metrics) histogram(name string) metrics.Histogram {
+	return metrics.GetOrRegisterHistogram(name, m.registry, metrics.NewExpDecaySample(1028, 0.015))
+}
+
+// EventHandled records the outcome of a webhook delivery of the given type and
+// action, which took d over all its attempts. It must be called once per
+// delivery, not once per attempt.
+func (m *Metrics) EventHandled(eventType, action string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.counter("events.handled[event:%s,action:%s,result:%s]", eventType, action, result).Inc(1)
+	metrics.GetOrRegisterTimer(fmt.Sprintf("events.latency[event:%s]", eventType), m.registry).Update(d)
+}
+
+// ConfigLoadFailed records a failure to load the config of the given
+// repository.
+func (m *Metrics) ConfigLoadFailed(owner, repoName string) {
+	if m == nil {
+		return
+	}
+	m.counter("config.load-failures[repo:%s/%s]", owner, repoName).Inc(1)
+}
+
+// autoMergeEvaluated records the outcome of an auto-merge evaluation. A PR is
+// evaluated again on most of its events, so the same outcome is recorded as
+// many times as it is evaluated.
+func (m *Metrics) autoMergeEvaluated(outcome string) {
+	if m == nil {
+		return
+	}
+	m.counter("automerge.evaluations[outcome:%s]", outcome).Inc(1)
+}
+
+// labelsChanged records the labels added, or removed, because of the given
+// rule of the config. Only the changes performed are recorded, not the ones
+// planned in shadow mode.
+func (m *Metrics) labelsChanged(rule, change string, n int) {
+	if m == nil {
+		return
+	}
+	m.counter("labels.%s[rule:%s]", change, rule).Inc(int64(n))
+}
+
+// mergeability records the conclusion of a Mergeability check.
+func (m *Metrics) mergeability(blockPR bool) {
+	if m == nil {
+		return
+	}
+	result := "pass"
+	if blockPR {
+		result = "fail"
+	}
+	m.counter("mergeability[result:%s]", result).Inc(1)
+}
+
+// commitViolations records the commits that do not match a message required
+// by the config.
+func (m *Metrics) commitViolations(n int) {
+	if m == nil {
+		return
+	}
+	m.counter("commits.violations").Inc(int64(n))
+}
+
+// flakeTriaged records the outcome of the triage of a test failure along with
+// the similarity, in percent, of the failure to the flake it matched, if any.
+func (m *Metrics) flakeTriaged(outcome string, similarity float64) {
+	if m == nil {
+		return
+	}
+	m.counter("flakes.triaged[outcome:%s]", outcome).Inc(1)
+	if outcome != flakeNotFound {
+		m.histogram("flakes.similarity").Update(int64(100 * similarity))
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	srv, c := newFakeClient(t)
+	srv.AddPR("cilium", "cilium", githubtest.PR{
+		Number:  1,
+		Author:  "alice",
+		Labels:  []string{"ready-to-merge"},
+		Commits: []githubtest.Commit{{SHA: "abc", Author: "alice"}},
+	})
+	c.SetMetrics(NewMetrics(registry))
+
+	ctx := context.Background()
+	assert.NoError(t, c.addLabels(ctx, ruleAutoLabel, "cilium", "cilium", 1, []string{"kind/bug", "sig/ci"}))
+	assert.NoError(t, c.removeLabel(ctx, ruleAutoMerge, "cilium", "cilium", 1, "ready-to-merge"))
+	// The changes only planned in shadow mode are not recorded.
+	c.SetShadowMode(true)
+	assert.NoError(t, c.addLabels(ctx, ruleAutoLabel, "cilium", "cilium", 1, []string{"kind/feature"}))
+	assert.NoError(t, c.removeLabel(ctx, ruleAutoMerge, "cilium", "cilium", 1, "kind/bug"))
+	c.metrics.mergeability(true)
+	c.metrics.autoMergeEvaluated((&autoMergeStatus{Approvals: 1, MinimalApprovals: 2}).outcome())
+	c.metrics.flakeTriaged(flakeKnownIssue, 0.9)
+	c.metrics.flakeTriaged(flakeNotFound, 0)
+	c.Metrics().EventHandled("pull_request", "opened", time.Second, errors.New("failed"))
+	c.Metrics().ConfigLoadFailed("cilium", "cilium")
+
+	count := func(name string) int64 {
+		counter, ok := registry.Get(name).(metrics.Counter)
+		if !assert.True(t, ok, "counter %q not registered", name) {
+			return 0
+		}
+		return counter.Count()
+	}
+	assert.Equal(t, int64(2), count("labels.added[rule:auto-label]"))
+	assert.Equal(t, int64(1), count("labels.removed[rule:auto-merge]"))
+	assert.Equal(t, int64(1), count("mergeability[result:fail]"))
+	assert.Equal(t, int64(1), count("automerge.evaluations[outcome:missing-approvals]"))
+	assert.Equal(t, int64(1), count("flakes.triaged[outcome:known-issue]"))
+	assert.Equal(t, int64(1), count("flakes.triaged[outcome:not-found]"))
+	assert.Equal(t, int64(1), count("events.handled[event:pull_request,action:opened,result:error]"))
+	assert.Equal(t, int64(1), count("config.load-failures[repo:cilium/cilium]"))
+	assert.Equal(t, int64(1), registry.Get("flakes.similarity").(metrics.Histogram).Count())
+	assert.Equal(t, int64(1), registry.Get("events.latency[event:pull_request]").(metrics.Timer).Count())
+
+	// Clients without metrics record nothing.
+	c.SetShadowMode(false)
+	c.SetMetrics(nil)
+	assert.NoError(t, c.addLabels(ctx, ruleAutoLabel, "cilium", "cilium", 1, []string{"kind/bug"}))
+	c.Metrics().EventHandled("status", "none", time.Second, nil)
+	assert.Equal(t, int64(2), count("labels.added[rule:auto-label]"))
+}
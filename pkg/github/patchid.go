@@ -0,0 +1,152 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	gh "github.com/google/go-github/v84/github"
+)
+
+// diffPatchID returns an identifier of the changes of the given unified diff,
+// similar to 'git patch-id --stable'. Line numbers, blob hashes, whitespace
+// and the order of the files are ignored so that the same changes applied on
+// top of a different base have the same patch ID.
+func diffPatchID(diff string) string {
+	var (
+		files []string
+		file  = sha256.New()
+		dirty bool
+	)
+	flush := func() {
+		if dirty {
+			files = append(files, hex.EncodeToString(file.Sum(nil)))
+		}
+		file.Reset()
+		dirty = false
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+		case strings.HasPrefix(line, "index "):
+			continue
+		case strings.HasPrefix(line, "@@"):
+			// Drop the line numbers, and the function context, of the hunk.
+			line = "@@"
+		}
+		line = strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, line)
+		if line == "" {
+			continue
+		}
+		file.Write([]byte(line))
+		file.Write([]byte{'\n'})
+		dirty = true
+	}
+	flush()
+
+	sort.Strings(files)
+	id := sha256.Sum256([]byte(strings.Join(files, "\n")))
+	return hex.EncodeToString(id[:])
+}
+
+// staleReviews decides if a review was done on changes that differ from the
+// current head of a PR.
+type staleReviews struct {
+	c        *Client
+	owner    string
+	repoName string
+	baseRef  string
+	headSHA  string
+	// headDate is the committer date of the head commit. It is used for
+	// reviews without a commit ID, or whose changes can't be compared.
+	headDate time.Time
+
+	patchIDs map[string]patchIDResult
+}
+
+type patchIDResult struct {
+	id  string
+	err error
+}
+
+// patchID returns the patch ID of the PR diff at the given commit, i.e., the
+// changes between the merge base of the commit with the base branch and the
+// commit itself. It returns an empty string if the commit no longer exists,
+// and an error if the diff can't be fetched, e.g., because it is too large.
+// Both the patch IDs and the errors are cached.
+func (sr *staleReviews) patchID(ctx context.Context, sha string) (string, error) {
+	if res, ok := sr.patchIDs[sha]; ok {
+		return res.id, res.err
+	}
+	var res patchIDResult
+	diff, _, err := sr.c.GHClient.Repositories.CompareCommitsRaw(ctx, sr.owner, sr.repoName, sr.baseRef, sha, gh.RawOptions{Type: gh.Diff})
+	switch {
+	case IsNotFound(err):
+	case err != nil:
+		res.err = err
+	default:
+		res.id = diffPatchID(diff)
+	}
+	if sr.patchIDs == nil {
+		sr.patchIDs = map[string]patchIDResult{}
+	}
+	sr.patchIDs[sha] = res
+	return res.id, res.err
+}
+
+// isStale returns true if the changes reviewed in the given review differ from
+// the changes at the head of the PR. A rebase, without any other changes, does
+// not make a review stale. If the changes can't be compared, the review is
+// stale if it was submitted before the head commit was committed.
+func (sr *staleReviews) isStale(ctx context.Context, review *gh.PullRequestReview) bool {
+	reviewedSHA := review.GetCommitID()
+	switch reviewedSHA {
+	case sr.headSHA:
+		return false
+	case "":
+		return review.GetSubmittedAt().Before(sr.headDate)
+	}
+
+	reviewedID, err := sr.patchID(ctx, reviewedSHA)
+	if err == nil && reviewedID == "" {
+		// The reviewed commit is gone so the changes can't be compared.
+		return true
+	}
+	var headID string
+	if err == nil {
+		headID, err = sr.patchID(ctx, sr.headSHA)
+	}
+	if err != nil {
+		sr.c.log.Info().Err(err).Fields(map[string]interface{}{
+			"review-id":    review.GetID(),
+			"reviewed-sha": reviewedSHA,
+			"head-sha":     sr.headSHA,
+		}).Msg("Unable to compare the reviewed changes, comparing the review and commit dates instead")
+		return review.GetSubmittedAt().Before(sr.headDate)
+	}
+	return reviewedID != headID
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testDiffFoo = `diff --git a/foo.go b/foo.go
+index 1111111..2222222 100644
+--- a/foo.go
++++ b/foo.go
+@@ -10,6 +10,7 @@ func foo() {
+ 	a := 1
++	b := 2
+ 	return a
+`
+	testDiffBar = `diff --git a/bar.go b/bar.go
+index 3333333..4444444 100644
+--- a/bar.go
++++ b/bar.go
+@@ -1,3 +1,3 @@
+-package bar
++package baz
+`
+)
+
+func Test_diffPatchID(t *testing.T) {
+	id := diffPatchID(testDiffFoo + testDiffBar)
+
+	rebased := `diff --git a/bar.go b/bar.go
+index 5555555..6666666 100644
+--- a/bar.go
++++ b/bar.go
+@@ -1,3 +1,3 @@
+-package bar
++package baz
+diff --git a/foo.go b/foo.go
+index 7777777..8888888 100644
+--- a/foo.go
++++ b/foo.go
+@@ -42,6 +42,7 @@ func foo() {
+ 	a := 1
++	b := 2
+ 	return a
+`
+	assert.Equal(t, id, diffPatchID(rebased), "rebased changes must have the same patch ID")
+
+	changed := testDiffBar + `diff --git a/foo.go b/foo.go
+index 1111111..2222222 100644
+--- a/foo.go
++++ b/foo.go
+@@ -10,6 +10,7 @@ func foo() {
+ 	a := 1
++	b := 3
+ 	return a
+`
+	assert.NotEqual(t, id, diffPatchID(changed), "different changes must have different patch IDs")
+	assert.NotEqual(t, id, diffPatchID(testDiffFoo), "dropped files must change the patch ID")
+}
+
+func TestStaleReviews_isStale(t *testing.T) {
+	// diffs maps the compared SHAs to their diff against "main", or to the
+	// status of the error response if the diff is not available.
+	diffs := map[string]interface{}{
+		"head":    testDiffFoo + testDiffBar,
+		"rebased": testDiffBar + testDiffFoo,
+		"changed": testDiffFoo,
+		"large":   http.StatusNotAcceptable,
+		"gone":    http.StatusNotFound,
+	}
+	var compares int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compares++
+		sha, ok := strings.CutPrefix(r.URL.Path, "/repos/cilium/cilium/compare/main...")
+		diff, found := diffs[sha]
+		if !ok || !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if status, ok := diff.(int); ok {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(diff.(string)))
+	}))
+	defer srv.Close()
+
+	headDate := time.Now()
+	newStaleReviews := func(headSHA string) *staleReviews {
+		ghClient := gh.NewClient(nil)
+		ghClient.BaseURL, _ = url.Parse(srv.URL + "/")
+		log := zerolog.Nop()
+		return &staleReviews{
+			c:        NewClientFromGHClient(ghClient, nil, "cilium", "cilium", &log),
+			owner:    "cilium",
+			repoName: "cilium",
+			baseRef:  "main",
+			headSHA:  headSHA,
+			headDate: headDate,
+		}
+	}
+	before := &gh.Timestamp{Time: headDate.Add(-time.Hour)}
+	after := &gh.Timestamp{Time: headDate.Add(time.Hour)}
+	tests := []struct {
+		name        string
+		commitID    string
+		submittedAt *gh.Timestamp
+		want        bool
+	}{
+		{name: "head reviewed", commitID: "head", submittedAt: before, want: false},
+		{name: "rebased without changes", commitID: "rebased", submittedAt: before, want: false},
+		{name: "changed", commitID: "changed", submittedAt: after, want: true},
+		{name: "reviewed commit gone", commitID: "gone", submittedAt: after, want: true},
+		{name: "no commit ID before head", submittedAt: before, want: true},
+		{name: "no commit ID after head", submittedAt: after, want: false},
+		{name: "diff too large before head", commitID: "large", submittedAt: before, want: true},
+		{name: "diff too large after head", commitID: "large", submittedAt: after, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := newStaleReviews("head")
+			review := &gh.PullRequestReview{CommitID: new(tt.commitID), SubmittedAt: tt.submittedAt}
+			assert.Equal(t, tt.want, sr.isStale(context.Background(), review))
+		})
+	}
+
+	// Diffs, and failures to get them, are only fetched once.
+	compares = 0
+	sr := newStaleReviews("large")
+	for range 2 {
+		assert.True(t, sr.isStale(context.Background(), &gh.PullRequestReview{CommitID: new("changed"), SubmittedAt: before}))
+	}
+	assert.Equal(t, 2, compares)
+}
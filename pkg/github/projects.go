@@ -0,0 +1,280 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// projectStatusField is the name of the single-select field of a GitHub
+// Projects (v2) board that represents the board columns.
+const projectStatusField = "Status"
+
+// ProjectColumn identifies a column of a GitHub Projects (v2) board.
+type ProjectColumn struct {
+	// Project is the URL of the project, for example
+	// https://github.com/orgs/cilium/projects/80
+	Project string `yaml:"project,omitempty"`
+	// Column is the value of the "Status" field that the project item will
+	// be set to.
+	Column string `yaml:"column,omitempty"`
+}
+
+// IsSet returns true if both the project and the column are configured.
+func (pc ProjectColumn) IsSet() bool {
+	return pc.Project != "" && pc.Column != ""
+}
+
+// parseProjectURL returns the type of the owner ("orgs" or "users"), the
+// owner login and the project number of the given Projects (v2) URL.
+func parseProjectURL(projectURL string) (ownerType, owner string, number int, err error) {
+	u, err := url.Parse(projectURL)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid project URL %q: %w", projectURL, err)
+	}
+	path := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(path) < 4 || path[2] != "projects" {
+		return "", "", 0, fmt.Errorf("invalid project URL %q", projectURL)
+	}
+	switch path[0] {
+	case "orgs", "users":
+	default:
+		return "", "", 0, fmt.Errorf("invalid project URL %q: only organization and user projects are supported", projectURL)
+	}
+	number, err = strconv.Atoi(path[3])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid project number in URL %q: %w", projectURL, err)
+	}
+	return path[0], path[1], number, nil
+}
+
+type projectV2 struct {
+	ID    githubv4.ID
+	Field struct {
+		ProjectV2SingleSelectField struct {
+			ID      githubv4.ID
+			Options []struct {
+				ID   githubv4.String
+				Name githubv4.String
+			}
+		} `graphql:"... on ProjectV2SingleSelectField"`
+	} `graphql:"field(name: $field)"`
+}
+
+// getProjectV2 returns the project, and its "Status" field, referenced by the
+// given URL.
+func (c *Client) getProjectV2(ctx context.Context, projectURL string) (*projectV2, error) {
+	ownerType, owner, number, err := parseProjectURL(projectURL)
+	if err != nil {
+		return nil, err
+	}
+	variables := map[string]interface{}{
+		"login":  githubv4.String(owner),
+		"number": githubv4.Int(number),
+		"field":  githubv4.String(projectStatusField),
+	}
+
+	var project projectV2
+	switch ownerType {
+	case "orgs":
+		var q struct {
+			Organization struct {
+				ProjectV2 projectV2 `graphql:"projectV2(number: $number)"`
+			} `graphql:"organization(login: $login)"`
+		}
+		err = c.GHV4Client.Query(ctx, &q, variables)
+		project = q.Organization.ProjectV2
+	case "users":
+		var q struct {
+			User struct {
+				ProjectV2 projectV2 `graphql:"projectV2(number: $number)"`
+			} `graphql:"user(login: $login)"`
+		}
+		err = c.GHV4Client.Query(ctx, &q, variables)
+		project = q.User.ProjectV2
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get project %q: %w", projectURL, err)
+	}
+	return &project, nil
+}
+
+// setProjectColumn adds the issue or PR with the given number and node ID to
+// the project and sets its "Status" field to the configured column. Adding an
+// item that already exists in the project is a no-op so this function can be
+// used to move items between columns.
+func (c *Client) setProjectColumn(ctx context.Context, rule string, pc ProjectColumn, number int, contentID string) error {
+	project, err := c.getProjectV2(ctx, pc.Project)
+	if err != nil {
+		return err
+	}
+
+	statusField := project.Field.ProjectV2SingleSelectField
+	var optionID githubv4.String
+	for _, opt := range statusField.Options {
+		if string(opt.Name) == pc.Column {
+			optionID = opt.ID
+			break
+		}
+	}
+	if optionID == "" {
+		return fmt.Errorf("column %q not found in %q field of project %q", pc.Column, projectStatusField, pc.Project)
+	}
+
+	args := map[string]interface{}{
+		"project":    pc.Project,
+		"column":     pc.Column,
+		"content-id": contentID,
+	}
+	err = c.mutate(rule, "set-project-column", c.orgName, c.repoName, number, args, func() error {
+		var addItem struct {
+			AddProjectV2ItemById struct {
+				Item struct {
+					ID githubv4.ID
+				}
+			} `graphql:"addProjectV2ItemById(input: $input)"`
+		}
+		err := c.GHV4Client.Mutate(ctx, &addItem, githubv4.AddProjectV2ItemByIdInput{
+			ProjectID: project.ID,
+			ContentID: githubv4.ID(contentID),
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("unable to add item to project %q: %w", pc.Project, err)
+		}
+
+		var updateField struct {
+			UpdateProjectV2ItemFieldValue struct {
+				ProjectV2Item struct {
+					ID githubv4.ID
+				}
+			} `graphql:"updateProjectV2ItemFieldValue(input: $input)"`
+		}
+		err = c.GHV4Client.Mutate(ctx, &updateField, githubv4.UpdateProjectV2ItemFieldValueInput{
+			ProjectID: project.ID,
+			ItemID:    addItem.AddProjectV2ItemById.Item.ID,
+			FieldID:   statusField.ID,
+			Value: githubv4.ProjectV2FieldValue{
+				SingleSelectOptionID: &optionID,
+			},
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("unable to set column %q in project %q: %w", pc.Column, pc.Project, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.log.Info().Fields(map[string]interface{}{
+		"project": pc.Project,
+		"column":  pc.Column,
+	}).Msg("Set project column")
+	return nil
+}
+
+// MoveToProjectsForLabelsXORed maps a branch to a set of labels and the
+// project column that PRs are moved to once that label is set. If two or more
+// labels of the same branch are set, PRs are not moved for that branch.
+type MoveToProjectsForLabelsXORed map[string]map[string]ProjectColumn
+
+// ProjectColumns returns the project column, per branch, that the PR should
+// be in based on its labels. Branches for which more than one label is set are
+// returned separately, mapped to the conflicting labels.
+func (m MoveToProjectsForLabelsXORed) ProjectColumns(prLabels PRLabels) (map[string]ProjectColumn, map[string][]string) {
+	columns := map[string]ProjectColumn{}
+	conflicts := map[string][]string{}
+	for branch, lblsToProjects := range m {
+		var setLabels []string
+		for lbl := range lblsToProjects {
+			if _, ok := prLabels[lbl]; ok {
+				setLabels = append(setLabels, lbl)
+			}
+		}
+		switch len(setLabels) {
+		case 0:
+		case 1:
+			columns[branch] = lblsToProjects[setLabels[0]]
+		default:
+			sort.Strings(setLabels)
+			conflicts[branch] = setLabels
+		}
+	}
+	return columns, conflicts
+}
+
+// BlockReasons returns the reasons to block the mergeability of a PR, one for
+// each branch with conflicting labels set.
+func (m MoveToProjectsForLabelsXORed) BlockReasons(prLabels PRLabels) []string {
+	_, conflicts := m.ProjectColumns(prLabels)
+	branches := make([]string, 0, len(conflicts))
+	for branch := range conflicts {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	blockReasons := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		blockReasons = append(blockReasons, fmt.Sprintf(
+			"Labels %s are mutually exclusive for branch %s, only one of them can be set",
+			strings.Join(conflicts[branch], ", "), branch))
+	}
+	return blockReasons
+}
+
+// MoveToProjectsForLabelsXORed moves the PR, with the given node ID, into the
+// project column of each branch that has the given label configured, as long
+// as the labels of that branch are not conflicting.
+func (c *Client) MoveToProjectsForLabelsXORed(ctx context.Context, cfg MoveToProjectsForLabelsXORed, prNumber int, contentID, label string, prLabels PRLabels) error {
+	columns, conflicts := cfg.ProjectColumns(prLabels)
+
+	branches := make([]string, 0, len(cfg))
+	for branch := range cfg {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	for _, branch := range branches {
+		// Only move the PR in the projects of the branch affected by the
+		// label that was added or removed.
+		if _, ok := cfg[branch][label]; !ok {
+			continue
+		}
+		if lbls, ok := conflicts[branch]; ok {
+			c.log.Info().Fields(map[string]interface{}{
+				"branch":    branch,
+				"labels":    lbls,
+				"pr-number": prNumber,
+			}).Msg("Not moving PR in project due to conflicting labels")
+			continue
+		}
+		pc, ok := columns[branch]
+		if !ok || !pc.IsSet() {
+			continue
+		}
+		err := c.setProjectColumn(ctx, ruleMoveToProjects, pc, prNumber, contentID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseProjectURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		wantOwnerType string
+		wantOwner     string
+		wantNumber    int
+		wantErr       assert.ErrorAssertionFunc
+	}{
+		{
+			name:          "organization project",
+			url:           "https://github.com/orgs/cilium/projects/80",
+			wantOwnerType: "orgs",
+			wantOwner:     "cilium",
+			wantNumber:    80,
+			wantErr:       assert.NoError,
+		},
+		{
+			name:          "user project view",
+			url:           "https://github.com/users/aanm/projects/1/views/2",
+			wantOwnerType: "users",
+			wantOwner:     "aanm",
+			wantNumber:    1,
+			wantErr:       assert.NoError,
+		},
+		{
+			name:    "classic repository project",
+			url:     "https://github.com/cilium/cilium/projects/80",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid project number",
+			url:     "https://github.com/orgs/cilium/projects/foo",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOwnerType, gotOwner, gotNumber, err := parseProjectURL(tt.url)
+			if !tt.wantErr(t, err, fmt.Sprintf("parseProjectURL(%v)", tt.url)) {
+				return
+			}
+			assert.Equalf(t, tt.wantOwnerType, gotOwnerType, "parseProjectURL(%v)", tt.url)
+			assert.Equalf(t, tt.wantOwner, gotOwner, "parseProjectURL(%v)", tt.url)
+			assert.Equalf(t, tt.wantNumber, gotNumber, "parseProjectURL(%v)", tt.url)
+		})
+	}
+}
+
+func TestMoveToProjectsForLabelsXORed_ProjectColumns(t *testing.T) {
+	cfg := MoveToProjectsForLabelsXORed{
+		"v1.6": {
+			"needs-backport/1.6": {
+				Project: "https://github.com/orgs/cilium/projects/1",
+				Column:  "Needs backport from master",
+			},
+			"backport-pending/1.6": {
+				Project: "https://github.com/orgs/cilium/projects/1",
+				Column:  "Backport pending to v1.6",
+			},
+		},
+		"v1.5": {
+			"needs-backport/1.5": {
+				Project: "https://github.com/orgs/cilium/projects/2",
+				Column:  "Needs backport from master",
+			},
+			"backport-pending/1.5": {
+				Project: "https://github.com/orgs/cilium/projects/2",
+				Column:  "Backport pending to v1.5",
+			},
+		},
+	}
+	prLabels := PRLabels{
+		"needs-backport/1.6":   {},
+		"needs-backport/1.5":   {},
+		"backport-pending/1.5": {},
+		"release-note/bug":     {},
+	}
+
+	columns, conflicts := cfg.ProjectColumns(prLabels)
+	assert.Equal(t, map[string]ProjectColumn{
+		"v1.6": {
+			Project: "https://github.com/orgs/cilium/projects/1",
+			Column:  "Needs backport from master",
+		},
+	}, columns)
+	assert.Equal(t, map[string][]string{
+		"v1.5": {"backport-pending/1.5", "needs-backport/1.5"},
+	}, conflicts)
+	assert.Equal(t, []string{
+		"Labels backport-pending/1.5, needs-backport/1.5 are mutually exclusive for branch v1.5, only one of them can be set",
+	}, cfg.BlockReasons(prLabels))
+}
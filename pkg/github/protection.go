@@ -0,0 +1,99 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"errors"
+
+	gh "github.com/google/go-github/v84/github"
+)
+
+// anyApp is the app ID of required checks that can be provided by any GitHub
+// App or by a commit status.
+const anyApp int64 = 0
+
+// requiredChecks maps the name of a required check to the ID of the GitHub
+// App that must provide it, or anyApp.
+type requiredChecks map[string]int64
+
+// add adds the check to the set of required checks. If the same check is
+// required by multiple sources, the one pinned to a GitHub App wins.
+func (rc requiredChecks) add(name string, appID int64) {
+	if appID < 0 {
+		// -1 explicitly allows any app to provide the check.
+		appID = anyApp
+	}
+	if prevAppID, ok := rc[name]; ok && prevAppID != anyApp {
+		return
+	}
+	rc[name] = appID
+}
+
+// getRequiredChecks returns the checks required to merge PRs into the given
+// branch. Required checks are merged from the classic branch protection and
+// from all repository and organization rulesets that apply to the branch.
+// Unprotected branches do not have any required checks.
+func (c *Client) getRequiredChecks(ctx context.Context, owner, repoName, branch string) (requiredChecks, error) {
+	rc := requiredChecks{}
+
+	brProt, _, err := c.GHClient.Repositories.GetBranchProtection(ctx, owner, repoName, branch)
+	switch {
+	case errors.Is(err, gh.ErrBranchNotProtected) || IsNotFound(err):
+		// Branch without classic branch protection.
+	case err != nil:
+		return nil, err
+	default:
+		rsc := brProt.GetRequiredStatusChecks()
+		switch {
+		case rsc == nil:
+		case rsc.Checks != nil:
+			for _, chk := range *rsc.Checks {
+				rc.add(chk.Context, chk.GetAppID())
+			}
+		case rsc.Contexts != nil:
+			for _, ctx := range *rsc.Contexts {
+				rc.add(ctx, anyApp)
+			}
+		}
+	}
+
+	nextPage := 0
+	for {
+		rules, resp, err := c.GHClient.Repositories.GetRulesForBranch(ctx, owner, repoName, branch, &gh.ListOptions{
+			Page: nextPage,
+		})
+		if err != nil && !IsNotFound(err) {
+			return nil, err
+		}
+		if rules != nil {
+			for _, rule := range rules.RequiredStatusChecks {
+				for _, chk := range rule.Parameters.RequiredStatusChecks {
+					appID := anyApp
+					if chk.IntegrationID != nil {
+						appID = *chk.IntegrationID
+					}
+					rc.add(chk.Context, appID)
+				}
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+
+	return rc, nil
+}
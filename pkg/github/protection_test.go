@@ -0,0 +1,150 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_requiredChecks_add(t *testing.T) {
+	type check struct {
+		name  string
+		appID int64
+	}
+	tests := []struct {
+		name   string
+		checks []check
+		want   requiredChecks
+	}{
+		{
+			name:   "any app",
+			checks: []check{{"ci/build", anyApp}},
+			want:   requiredChecks{"ci/build": anyApp},
+		},
+		{
+			name:   "explicitly any app",
+			checks: []check{{"ci/build", -1}},
+			want:   requiredChecks{"ci/build": anyApp},
+		},
+		{
+			name:   "pinned after any app",
+			checks: []check{{"ci/build", anyApp}, {"ci/build", 15368}},
+			want:   requiredChecks{"ci/build": 15368},
+		},
+		{
+			name:   "any app after pinned",
+			checks: []check{{"ci/build", 15368}, {"ci/build", -1}},
+			want:   requiredChecks{"ci/build": 15368},
+		},
+		{
+			name:   "first pinned app wins",
+			checks: []check{{"ci/build", 15368}, {"ci/build", 42}},
+			want:   requiredChecks{"ci/build": 15368},
+		},
+		{
+			name:   "different checks",
+			checks: []check{{"ci/build", 15368}, {"ci/test", anyApp}},
+			want:   requiredChecks{"ci/build": 15368, "ci/test": anyApp},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := requiredChecks{}
+			for _, chk := range tt.checks {
+				rc.add(chk.name, chk.appID)
+			}
+			assert.Equal(t, tt.want, rc)
+		})
+	}
+}
+
+func TestClient_getRequiredChecks(t *testing.T) {
+	tests := []struct {
+		name string
+		// contexts and checks are the required checks of the classic
+		// branch protection, if any.
+		contexts []string
+		checks   []*gh.RequiredStatusCheck
+		rulesets [][]*gh.RuleStatusCheck
+		want     requiredChecks
+	}{
+		{
+			name: "unprotected branch",
+			want: requiredChecks{},
+		},
+		{
+			name:     "classic protection with contexts",
+			contexts: []string{"ci/build", "ci/test"},
+			want:     requiredChecks{"ci/build": anyApp, "ci/test": anyApp},
+		},
+		{
+			name: "classic protection pinned to apps",
+			checks: []*gh.RequiredStatusCheck{
+				{Context: "ci/build", AppID: new(int64(15368))},
+				{Context: "ci/test", AppID: new(int64(-1))},
+				{Context: "ci/lint"},
+			},
+			want: requiredChecks{"ci/build": 15368, "ci/test": anyApp, "ci/lint": anyApp},
+		},
+		{
+			name: "rulesets only",
+			rulesets: [][]*gh.RuleStatusCheck{
+				{{Context: "ci/build", IntegrationID: new(int64(15368))}},
+				{{Context: "ci/test"}},
+			},
+			want: requiredChecks{"ci/build": 15368, "ci/test": anyApp},
+		},
+		{
+			name:     "ruleset pins a check of the classic protection",
+			contexts: []string{"ci/build", "ci/test"},
+			rulesets: [][]*gh.RuleStatusCheck{
+				{{Context: "ci/build", IntegrationID: new(int64(15368))}},
+			},
+			want: requiredChecks{"ci/build": 15368, "ci/test": anyApp},
+		},
+		{
+			name: "classic protection pins a check of a ruleset",
+			checks: []*gh.RequiredStatusCheck{
+				{Context: "ci/build", AppID: new(int64(15368))},
+			},
+			rulesets: [][]*gh.RuleStatusCheck{
+				{{Context: "ci/build"}, {Context: "ci/e2e", IntegrationID: new(int64(42))}},
+			},
+			want: requiredChecks{"ci/build": 15368, "ci/e2e": 42},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			switch {
+			case tt.contexts != nil:
+				srv.SetBranchProtection("cilium", "cilium", "main", tt.contexts...)
+			case tt.checks != nil:
+				srv.SetBranchProtectionChecks("cilium", "cilium", "main", tt.checks...)
+			}
+			for _, checks := range tt.rulesets {
+				srv.AddRuleset("cilium", "cilium", "main", checks...)
+			}
+
+			got, err := c.getRequiredChecks(context.Background(), "cilium", "cilium", "main")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
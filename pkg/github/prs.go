@@ -126,18 +126,23 @@ func (c *Client) getPRTriggerComment(ctx context.Context, orgName, repo string,
 	return triggeredComment, nil
 }
 
-func (c *Client) createComment(ctx context.Context, orgName, repo string, number int, body string) error {
-	_, _, err := c.GHClient.Issues.CreateComment(ctx, orgName, repo, number, &gh.IssueComment{
-		Body: &body,
+func (c *Client) createComment(ctx context.Context, rule, orgName, repo string, number int, body string) error {
+	return c.mutate(rule, "create-comment", orgName, repo, number, map[string]interface{}{"body": body}, func() error {
+		_, _, err := c.GHClient.Issues.CreateComment(ctx, orgName, repo, number, &gh.IssueComment{
+			Body: &body,
+		})
+		return err
 	})
-	return err
 }
 
-func (c *Client) editComment(ctx context.Context, orgName, repo string, commentID int64, body string) error {
-	_, _, err := c.GHClient.Issues.EditComment(ctx, orgName, repo, commentID, &gh.IssueComment{
-		Body: &body,
+func (c *Client) editComment(ctx context.Context, rule, orgName, repo string, number int, commentID int64, body string) error {
+	args := map[string]interface{}{"comment-id": commentID, "body": body}
+	return c.mutate(rule, "edit-comment", orgName, repo, number, args, func() error {
+		_, _, err := c.GHClient.Issues.EditComment(ctx, orgName, repo, commentID, &gh.IssueComment{
+			Body: &body,
+		})
+		return err
 	})
-	return err
 }
 
 // CreateOrAppendComment creates or appends the 'comment' into the last comment
@@ -163,13 +168,13 @@ func (c *Client) CreateOrAppendCommentIssueComment(ctx context.Context, prNumber
 	repoName := c.repoName
 
 	if issueComment == nil {
-		err := c.createComment(ctx, orgName, repoName, prNumber, comment)
+		err := c.createComment(ctx, ruleFlakeTracker, orgName, repoName, prNumber, comment)
 		if err != nil {
 			return err
 		}
 	} else {
 		body := issueComment.GetBody() + "\n\n" + comment
-		err := c.editComment(ctx, orgName, repoName, issueComment.GetID(), body)
+		err := c.editComment(ctx, ruleFlakeTracker, orgName, repoName, prNumber, issueComment.GetID(), body)
 		if err != nil {
 			return err
 		}
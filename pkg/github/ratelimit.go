@@ -0,0 +1,254 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/rcrowley/go-metrics"
+)
+
+const (
+	// rateLimitMaxRetries is the number of times a rate limited idempotent
+	// request is retried.
+	rateLimitMaxRetries = 3
+	// rateLimitMaxWait is the longest wait for a rate limit to reset when
+	// the request has no deadline.
+	rateLimitMaxWait = time.Hour
+	// secondaryRateLimitWait is the wait after hitting a secondary rate limit
+	// without a Retry-After header, as recommended by GitHub.
+	secondaryRateLimitWait = time.Minute
+)
+
+// RateLimiter is a middleware for the transport of GitHub clients that keeps
+// track of the remaining quota of each installation. Requests that would
+// exceed an exhausted quota wait for it to reset, and rate limited idempotent
+// requests are retried once the limit resets or after the time given in
+// their Retry-After header. It is safe for concurrent use.
+type RateLimiter struct {
+	registry metrics.Registry
+
+	mu sync.Mutex
+	// resets maps a quota, i.e. an installation and a rate limit resource,
+	// to the time it resets if it is exhausted.
+	resets map[string]time.Time
+
+	waits   metrics.Counter
+	retries metrics.Counter
+}
+
+// NewRateLimiter returns a RateLimiter that registers its metrics in the
+// given registry.
+func NewRateLimiter(registry metrics.Registry) *RateLimiter {
+	return &RateLimiter{
+		registry: registry,
+		resets:   map[string]time.Time{},
+		waits:    metrics.GetOrRegisterCounter("github.quota.waits", registry),
+		retries:  metrics.GetOrRegisterCounter("github.quota.retries", registry),
+	}
+}
+
+// Middleware returns the middleware for the transport of the clients of the
+// given installation.
+func (rl *RateLimiter) Middleware(installation string) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return rl.roundTrip(installation, next, r)
+		})
+	}
+}
+
+// SkipRateLimitCheck makes the given client, whose transport uses a
+// RateLimiter, send all requests to its transport. Otherwise, the client fails
+// requests with a RateLimitError, without sending them, once the last response
+// reported an exhausted quota, so that the RateLimiter never gets to wait for
+// the quota to reset. It returns the client.
+func SkipRateLimitCheck(client *gh.Client) *gh.Client {
+	client.DisableRateLimitCheck = true
+	return client
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+func (rl *RateLimiter) roundTrip(installation string, next http.RoundTripper, r *http.Request) (*http.Response, error) {
+	api := apiGroup(r.URL.Path)
+	quota := fmt.Sprintf("installation:%s,resource:%s", installation, rateLimitResource(r.URL.Path))
+
+	if reset, ok := rl.reset(quota); ok {
+		// If the quota does not reset before the deadline of the request,
+		// GitHub rejects it with the usual rate limit error, and the work
+		// queue retries the event once the quota resets.
+		rl.wait(r, time.Until(reset))
+	}
+
+	for attempt := 0; ; attempt++ {
+		metrics.GetOrRegisterCounter(fmt.Sprintf("github.quota.requests[installation:%s,api:%s]", installation, api), rl.registry).Inc(1)
+		resp, err := next.RoundTrip(r)
+		if err != nil {
+			return resp, err
+		}
+		// The resource of the response is authoritative.
+		if resource := resp.Header.Get("X-RateLimit-Resource"); resource != "" {
+			quota = fmt.Sprintf("installation:%s,resource:%s", installation, resource)
+		}
+		rl.update(quota, resp.Header)
+
+		wait, limited := rateLimitWait(resp)
+		if !limited || !isIdempotent(r.Method) || attempt == rateLimitMaxRetries {
+			return resp, nil
+		}
+		if !rl.wait(r, wait) {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		rl.retries.Inc(1)
+	}
+}
+
+// reset returns the time the given quota resets if it is exhausted.
+func (rl *RateLimiter) reset(quota string) (time.Time, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	reset, ok := rl.resets[quota]
+	if ok && time.Now().After(reset) {
+		delete(rl.resets, quota)
+		return time.Time{}, false
+	}
+	return reset, ok
+}
+
+// update records the quota reported in the headers of a response. The quota
+// itself is exported by githubapp.ClientMetrics.
+func (rl *RateLimiter) update(quota string, header http.Header) {
+	remaining, err := strconv.ParseInt(header.Get("X-RateLimit-Remaining"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if remaining > 0 {
+		delete(rl.resets, quota)
+		return
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.resets[quota] = time.Unix(reset, 0)
+	}
+}
+
+// wait waits for the given duration, unless it exceeds the deadline of the
+// request. It returns false if the request should not be retried after it.
+func (rl *RateLimiter) wait(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	if deadline, ok := r.Context().Deadline(); ok && time.Now().Add(d).After(deadline) {
+		return false
+	}
+	if d > rateLimitMaxWait {
+		return false
+	}
+	rl.waits.Inc(1)
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-r.Context().Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// rateLimitWait returns how long to wait before retrying a request that hit
+// the primary or a secondary rate limit.
+func rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		secs, err := strconv.ParseInt(retryAfter, 10, 64)
+		if err != nil {
+			return secondaryRateLimitWait, true
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return time.Until(time.Unix(reset, 0)), true
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return secondaryRateLimitWait, true
+	}
+	// A 403 without any rate limit header is a permission error.
+	return 0, false
+}
+
+// isIdempotent returns true if the request with the given method can be
+// retried without any side effect.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// rateLimitResource returns the rate limit resource a request to the given
+// path counts against.
+func rateLimitResource(path string) string {
+	switch {
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	case strings.Contains(path, "/search/"):
+		return "search"
+	}
+	return "core"
+}
+
+// apiGroup returns the group of endpoints of the given path, e.g. "pulls" or
+// "check-runs", which tells which feature consumes the quota.
+func apiGroup(path string) string {
+	path = strings.TrimPrefix(path, "/api/v3")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) == 0 || parts[0] == "":
+		return "other"
+	case parts[0] == "repos" && len(parts) >= 4:
+		// /repos/{owner}/{repo}/{group}/...
+		if parts[3] == "commits" && len(parts) >= 6 {
+			// e.g. /repos/{owner}/{repo}/commits/{ref}/check-runs
+			return parts[5]
+		}
+		return parts[3]
+	case parts[0] == "orgs" && len(parts) >= 3:
+		return parts[2]
+	}
+	return parts[0]
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_retries(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Remaining", "100")
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	registry := metrics.NewRegistry()
+	client := &http.Client{
+		Transport: NewRateLimiter(registry).Middleware("1")(http.DefaultTransport),
+	}
+
+	// Idempotent requests are retried after the secondary rate limit.
+	resp, err := client.Get(srv.URL + "/repos/cilium/cilium/pulls/1")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, int64(2), metrics.GetOrRegisterCounter("github.quota.requests[installation:1,api:pulls]", registry).Count())
+
+	// Other requests are not.
+	calls = 0
+	resp, err = client.Post(srv.URL+"/repos/cilium/cilium/issues/1/labels", "application/json", nil)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRateLimiter_exhaustedQuota(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls int
+		reset time.Time
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		if calls == 1 {
+			// The quota is exhausted by the first request.
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		} else {
+			w.Header().Set("X-RateLimit-Remaining", "4999")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"number": 1}`))
+	}))
+	defer srv.Close()
+
+	newClient := func() *gh.Client {
+		mu.Lock()
+		calls = 0
+		// The reset is rounded down to the second in the header.
+		reset = time.Now().Add(2 * time.Second)
+		mu.Unlock()
+		client := gh.NewClient(&http.Client{
+			Transport: NewRateLimiter(metrics.NewRegistry()).Middleware("1")(http.DefaultTransport),
+		})
+		client.BaseURL, _ = url.Parse(srv.URL + "/")
+		return client
+	}
+	ctx := context.Background()
+
+	// The rate limit check of go-github fails the request before it reaches
+	// the RateLimiter.
+	client := newClient()
+	_, _, err := client.PullRequests.Get(ctx, "cilium", "cilium", 1)
+	assert.NoError(t, err)
+	_, _, err = client.PullRequests.Get(ctx, "cilium", "cilium", 1)
+	var rateLimitErr *gh.RateLimitError
+	assert.ErrorAs(t, err, &rateLimitErr)
+	assert.Equal(t, 1, calls)
+
+	// Without it, the RateLimiter waits for the quota to reset.
+	client = SkipRateLimitCheck(newClient())
+	_, _, err = client.PullRequests.Get(ctx, "cilium", "cilium", 1)
+	assert.NoError(t, err)
+	start := time.Now()
+	pr, _, err := client.PullRequests.Get(ctx, "cilium", "cilium", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pr.GetNumber())
+	assert.Equal(t, 2, calls)
+	assert.Greater(t, time.Since(start), 500*time.Millisecond)
+}
+
+func Test_apiGroup(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/repos/cilium/cilium/pulls/1/reviews", want: "pulls"},
+		{path: "/repos/cilium/cilium/commits/abc/check-runs", want: "check-runs"},
+		{path: "/repos/cilium/cilium/commits/abc", want: "commits"},
+		{path: "/api/v3/repos/cilium/cilium/branches/main/protection", want: "branches"},
+		{path: "/orgs/cilium/teams/committers/members", want: "teams"},
+		{path: "/graphql", want: "graphql"},
+		{path: "/", want: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, apiGroup(tt.path))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+)
+
+// Scheduler runs the given evaluation of a PR of the client's repository
+// asynchronously, one at a time with the handling of the other events of the
+// PR, with a client of its own. Detached evaluations are not part of the
+// handling of the event being handled by the client, e.g., the evaluations run
+// once a debounce window expires.
+type Scheduler func(prNumber int, detached bool, eval func(c *Client) error) error
+
+// SetScheduler sets the scheduler of the evaluations of PRs triggered by
+// events that are not tied to a single PR, e.g., status and check run events.
+func (c *Client) SetScheduler(s Scheduler) {
+	c.scheduler = s
+}
+
+// schedule schedules the evaluation of the given PR with the client's
+// scheduler, or runs it right away with the client if it does not have one.
+func (c *Client) schedule(prNumber int, detached bool, eval func(c *Client) error) error {
+	if c.scheduler == nil {
+		return eval(c)
+	}
+	return c.scheduler(prNumber, detached, eval)
+}
+
+// baseConfig returns the config of the repository at the base SHA of the given
+// PR, or nil if the repository does not have a config there. The client is
+// switched to shadow mode if the config enables it.
+func (c *Client) baseConfig(pr *gh.PullRequest) (*PRBlockerConfig, error) {
+	_, cfg, err := LoadConfig(c, c.orgName, c.repoName, pr.GetBase().GetSHA())
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	if cfg.Mode == ModeShadow {
+		c.SetShadowMode(true)
+	}
+	return cfg, nil
+}
+
+// autoMergeEval returns the auto-merge evaluation of the given PR of the
+// client's repository. The PR is fetched once evaluated so that the
+// evaluation sees its latest state, and is evaluated with the config at its
+// base SHA.
+func autoMergeEval(prNumber int) func(c *Client) error {
+	return func(c *Client) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		pr, _, err := c.GHClient.PullRequests.Get(ctx, c.orgName, c.repoName, prNumber)
+		if err != nil {
+			return err
+		}
+		if pr.GetState() == "closed" || pr.GetDraft() {
+			return nil
+		}
+		cfg, err := c.baseConfig(pr)
+		if err != nil || cfg == nil {
+			return err
+		}
+		autoMergeCfg, ok := cfg.AutoMerge.ForBranch(pr.GetBase().GetRef())
+		if !ok {
+			return nil
+		}
+		return c.AutoMerge(autoMergeCfg, c.orgName, c.repoName, pr.GetBase(), pr.GetHead(), prNumber, parseGHLabels(pr.Labels), nil)
+	}
+}
+
+// scheduleReevaluation schedules the evaluation of the Mergeability check and
+// of the auto-merge conditions of the given PR of the client's repository.
+func (c *Client) scheduleReevaluation(prNumber int) error {
+	return c.schedule(prNumber, false, func(c *Client) error {
+		return c.reevaluatePR(c.orgName, c.repoName, prNumber)
+	})
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+
+	gh "github.com/google/go-github/v84/github"
+)
+
+// ModeShadow is the value of PRBlockerConfig.Mode that enables shadow mode for
+// the repository.
+const ModeShadow = "shadow"
+
+// PlannedAction is a mutation that a client in shadow mode recorded instead
+// of performing it.
+type PlannedAction struct {
+	Action string `json:"action"`
+	Owner  string `json:"owner"`
+	Repo   string `json:"repo"`
+	// Number is the number of the PR or issue, if any.
+	Number  int                    `json:"number,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// SetShadowMode sets whether the client records the mutations as planned
+// actions instead of performing them. Reads are always performed.
+func (c *Client) SetShadowMode(shadow bool) {
+	c.plannedMu.Lock()
+	defer c.plannedMu.Unlock()
+	c.shadow = shadow
+}
+
+// ShadowMode returns true if the client records the mutations as planned
+// actions instead of performing them.
+func (c *Client) ShadowMode() bool {
+	c.plannedMu.Lock()
+	defer c.plannedMu.Unlock()
+	return c.shadow
+}
+
+// PlannedActions returns the actions recorded in shadow mode.
+func (c *Client) PlannedActions() []PlannedAction {
+	c.plannedMu.Lock()
+	defer c.plannedMu.Unlock()
+	return append([]PlannedAction(nil), c.planned...)
+}
+
+// mutate performs a mutation caused by the given rule of the config, unless
+// the client is in shadow mode, in which case it is recorded as a planned
+// action instead. Either way, the mutation is recorded in the audit log.
+func (c *Client) mutate(rule, action, owner, repoName string, number int, args map[string]interface{}, do func() error) error {
+	c.plannedMu.Lock()
+	shadow := c.shadow
+	if shadow {
+		c.planned = append(c.planned, PlannedAction{
+			Action:  action,
+			Owner:   owner,
+			Repo:    repoName,
+			Number:  number,
+			Details: args,
+		})
+	}
+	c.plannedMu.Unlock()
+
+	var err error
+	if shadow {
+		c.log.Info().Fields(map[string]interface{}{
+			"action":  action,
+			"owner":   owner,
+			"repo":    repoName,
+			"number":  number,
+			"rule":    rule,
+			"details": args,
+		}).Msg("Shadow mode: planned action")
+	} else {
+		err = do()
+	}
+
+	r := AuditRecord{
+		Owner:  owner,
+		Repo:   repoName,
+		Number: number,
+		Action: action,
+		Rule:   rule,
+		Args:   args,
+		Shadow: shadow,
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	c.audit(r)
+	return err
+}
+
+func (c *Client) addLabels(ctx context.Context, rule, owner, repoName string, number int, labels []string) error {
+	err := c.mutate(rule, "add-labels", owner, repoName, number, map[string]interface{}{"labels": labels}, func() error {
+		_, _, err := c.GHClient.Issues.AddLabelsToIssue(ctx, owner, repoName, number, labels)
+		return err
+	})
+	if err == nil && !c.ShadowMode() {
+		c.metrics.labelsChanged(rule, "added", len(labels))
+	}
+	return err
+}
+
+func (c *Client) removeLabel(ctx context.Context, rule, owner, repoName string, number int, label string) error {
+	err := c.mutate(rule, "remove-label", owner, repoName, number, map[string]interface{}{"label": label}, func() error {
+		_, err := c.GHClient.Issues.RemoveLabelForIssue(ctx, owner, repoName, number, label)
+		return err
+	})
+	if err == nil && !c.ShadowMode() {
+		c.metrics.labelsChanged(rule, "removed", 1)
+	}
+	return err
+}
+
+func (c *Client) requestReviewers(ctx context.Context, rule, owner, repoName string, number int, reviewers []string) error {
+	return c.mutate(rule, "request-reviewers", owner, repoName, number, map[string]interface{}{"reviewers": reviewers}, func() error {
+		_, _, err := c.GHClient.PullRequests.RequestReviewers(ctx, owner, repoName, number, gh.ReviewersRequest{
+			Reviewers: reviewers,
+		})
+		return err
+	})
+}
+
+// createCheckRun creates the check run for the given PR, if any.
+func (c *Client) createCheckRun(ctx context.Context, rule, owner, repoName string, number int, opts gh.CreateCheckRunOptions) error {
+	args := map[string]interface{}{
+		"name":       opts.Name,
+		"head-sha":   opts.HeadSHA,
+		"conclusion": opts.GetConclusion(),
+		"title":      opts.GetOutput().GetTitle(),
+	}
+	return c.mutate(rule, "create-check-run", owner, repoName, number, args, func() error {
+		_, _, err := c.GHClient.Checks.CreateCheckRun(ctx, owner, repoName, opts)
+		return err
+	})
+}
+
+// updateCheckRun updates the check run of the given PR, if any.
+func (c *Client) updateCheckRun(ctx context.Context, rule, owner, repoName string, number int, checkRunID int64, opts gh.UpdateCheckRunOptions) error {
+	args := map[string]interface{}{
+		"name":         opts.Name,
+		"check-run-id": checkRunID,
+		"conclusion":   opts.GetConclusion(),
+		"title":        opts.GetOutput().GetTitle(),
+	}
+	return c.mutate(rule, "update-check-run", owner, repoName, number, args, func() error {
+		_, _, err := c.GHClient.Checks.UpdateCheckRun(ctx, owner, repoName, checkRunID, opts)
+		return err
+	})
+}
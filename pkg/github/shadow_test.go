@@ -0,0 +1,78 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_shadowMode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	ghClient := gh.NewClient(nil)
+	ghClient.BaseURL, _ = url.Parse(srv.URL + "/")
+	log := zerolog.Nop()
+	c := NewClientFromGHClient(ghClient, nil, "cilium", "cilium", &log)
+	c.SetShadowMode(true)
+
+	ctx := context.Background()
+	assert.NoError(t, c.AutoLabel([]string{"kind/bug"}, "cilium", "cilium", 1, PRLabels{}))
+	assert.NoError(t, c.createComment(ctx, ruleFlakeTracker, "cilium", "cilium", 1, "hello"))
+	assert.NoError(t, c.createCheckRun(ctx, ruleBlockPRWith, "cilium", "cilium", 1, gh.CreateCheckRunOptions{
+		Name:    mergeabilityCheckName,
+		HeadSHA: "abc",
+	}))
+
+	assert.Equal(t, []PlannedAction{
+		{
+			Action:  "add-labels",
+			Owner:   "cilium",
+			Repo:    "cilium",
+			Number:  1,
+			Details: map[string]interface{}{"labels": []string{"kind/bug"}},
+		},
+		{
+			Action:  "create-comment",
+			Owner:   "cilium",
+			Repo:    "cilium",
+			Number:  1,
+			Details: map[string]interface{}{"body": "hello"},
+		},
+		{
+			Action: "create-check-run",
+			Owner:  "cilium",
+			Repo:   "cilium",
+			Number: 1,
+			Details: map[string]interface{}{
+				"name":       mergeabilityCheckName,
+				"head-sha":   "abc",
+				"conclusion": "",
+				"title":      "",
+			},
+		},
+	}, c.PlannedActions())
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+)
+
+// TeamMembersCache caches the members of GitHub teams for a limited amount of
+// time. It is safe for concurrent use.
+type TeamMembersCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]teamMembersEntry
+}
+
+type teamMembersEntry struct {
+	members map[string]struct{}
+	expires time.Time
+}
+
+// NewTeamMembersCache returns a TeamMembersCache that keeps team members for
+// the given TTL.
+func NewTeamMembersCache(ttl time.Duration) *TeamMembersCache {
+	return &TeamMembersCache{
+		ttl:     ttl,
+		entries: map[string]teamMembersEntry{},
+	}
+}
+
+func (tc *TeamMembersCache) get(team string) (map[string]struct{}, bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	entry, ok := tc.entries[team]
+	if !ok || time.Now().After(entry.expires) {
+		delete(tc.entries, team)
+		return nil, false
+	}
+	return entry.members, true
+}
+
+func (tc *TeamMembersCache) set(team string, members map[string]struct{}) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.entries[team] = teamMembersEntry{
+		members: members,
+		expires: time.Now().Add(tc.ttl),
+	}
+}
+
+// SetTeamMembersCache sets the cache used to look up the members of teams.
+func (c *Client) SetTeamMembersCache(tc *TeamMembersCache) {
+	c.teamMembers = tc
+}
+
+// getTeamMembers returns the logins, in lower case, of all members of the
+// given team.
+func (c *Client) getTeamMembers(ctx context.Context, org, slug string) (map[string]struct{}, error) {
+	team := org + "/" + slug
+	if c.teamMembers != nil {
+		if members, ok := c.teamMembers.get(team); ok {
+			return members, nil
+		}
+	}
+
+	members := map[string]struct{}{}
+	nextPage := 0
+	for {
+		users, resp, err := c.GHClient.Teams.ListTeamMembersBySlug(ctx, org, slug, &gh.TeamListTeamMembersOptions{
+			ListOptions: gh.ListOptions{
+				Page:    nextPage,
+				PerPage: 100,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list members of team %s: %w", team, err)
+		}
+		for _, user := range users {
+			members[strings.ToLower(user.GetLogin())] = struct{}{}
+		}
+		nextPage = resp.NextPage
+		if nextPage == 0 {
+			break
+		}
+	}
+
+	if c.teamMembers != nil {
+		c.teamMembers.set(team, members)
+	}
+	return members, nil
+}
+
+// approvedByTeam returns true if any current member of the given team is
+// part of the approvers. Approvers must be lower case logins.
+func (c *Client) approvedByTeam(ctx context.Context, org, slug string, approvers map[string]struct{}) (bool, error) {
+	members, err := c.getTeamMembers(ctx, org, slug)
+	if err != nil {
+		return false, err
+	}
+	for member := range members {
+		if _, ok := approvers[member]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
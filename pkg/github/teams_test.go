@@ -0,0 +1,116 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamMembersCache(t *testing.T) {
+	srv, c := newFakeClient(t)
+	tc := NewTeamMembersCache(time.Hour)
+	c.SetTeamMembersCache(tc)
+	ctx := context.Background()
+
+	srv.SetTeamMembers("cilium", "committers", "alice")
+	members, err := c.getTeamMembers(ctx, "cilium", "committers")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"alice": {}}, members)
+
+	// Members are cached until the TTL expires.
+	srv.SetTeamMembers("cilium", "committers", "alice", "bob")
+	members, err = c.getTeamMembers(ctx, "cilium", "committers")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"alice": {}}, members)
+
+	// Expire the entry rather than waiting for it.
+	tc.mu.Lock()
+	entry := tc.entries["cilium/committers"]
+	entry.expires = time.Now().Add(-time.Second)
+	tc.entries["cilium/committers"] = entry
+	tc.mu.Unlock()
+
+	members, err = c.getTeamMembers(ctx, "cilium", "committers")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"alice": {}, "bob": {}}, members)
+
+	// Teams are cached separately.
+	srv.SetTeamMembers("cilium", "docs", "carol")
+	members, err = c.getTeamMembers(ctx, "cilium", "docs")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"carol": {}}, members)
+}
+
+func TestClient_approvedByTeam(t *testing.T) {
+	tests := []struct {
+		name      string
+		slug      string
+		approvers []string
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name:      "approved by a member",
+			slug:      "committers",
+			approvers: []string{"dave", "bob"},
+			want:      true,
+		},
+		{
+			name:      "logins of members are lower case",
+			slug:      "committers",
+			approvers: []string{"alice"},
+			want:      true,
+		},
+		{
+			name:      "approved by non-members",
+			slug:      "committers",
+			approvers: []string{"dave"},
+			want:      false,
+		},
+		{
+			name: "no approvals",
+			slug: "committers",
+			want: false,
+		},
+		{
+			name:      "unknown team",
+			slug:      "unknown",
+			approvers: []string{"alice"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			srv.SetTeamMembers("cilium", "committers", "Alice", "bob")
+			approvers := map[string]struct{}{}
+			for _, a := range tt.approvers {
+				approvers[a] = struct{}{}
+			}
+
+			got, err := c.approvedByTeam(context.Background(), "cilium", tt.slug, approvers)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// getUnresolvedThreads returns the URLs of the first comment of all review
+// threads of the given PR that are neither resolved nor outdated.
+func (c *Client) getUnresolvedThreads(ctx context.Context, owner, repoName string, prNumber int) ([]string, error) {
+	var q struct {
+		Repository struct {
+			PullRequest struct {
+				ReviewThreads struct {
+					Nodes []struct {
+						IsResolved githubv4.Boolean
+						IsOutdated githubv4.Boolean
+						Comments   struct {
+							Nodes []struct {
+								URL githubv4.URI
+							}
+						} `graphql:"comments(first: 1)"`
+					}
+					PageInfo struct {
+						EndCursor   githubv4.String
+						HasNextPage githubv4.Boolean
+					}
+				} `graphql:"reviewThreads(first: 100, after: $cursor)"`
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	variables := map[string]interface{}{
+		"owner":  githubv4.String(owner),
+		"name":   githubv4.String(repoName),
+		"number": githubv4.Int(prNumber),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	var urls []string
+	for {
+		err := c.GHV4Client.Query(ctx, &q, variables)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get review threads of PR %d: %w", prNumber, err)
+		}
+		threads := q.Repository.PullRequest.ReviewThreads
+		for _, thread := range threads.Nodes {
+			if thread.IsResolved || thread.IsOutdated || len(thread.Comments.Nodes) == 0 {
+				continue
+			}
+			urls = append(urls, thread.Comments.Nodes[0].URL.String())
+		}
+		if !threads.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = githubv4.NewString(threads.PageInfo.EndCursor)
+	}
+	return urls, nil
+}
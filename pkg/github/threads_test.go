@@ -0,0 +1,85 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cilium/github-actions/pkg/githubtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_getUnresolvedThreads(t *testing.T) {
+	threadURL := func(i int) string {
+		return fmt.Sprintf("https://github.com/cilium/cilium/pull/1#discussion_r%d", i)
+	}
+	// Threads are listed in pages of 100 threads.
+	var (
+		many     []githubtest.ReviewThread
+		manyURLs []string
+	)
+	for i := range 250 {
+		many = append(many, githubtest.ReviewThread{URL: threadURL(i), Resolved: i%2 == 0})
+		if i%2 != 0 {
+			manyURLs = append(manyURLs, threadURL(i))
+		}
+	}
+	tests := []struct {
+		name    string
+		threads []githubtest.ReviewThread
+		want    []string
+	}{
+		{
+			name: "no threads",
+			want: nil,
+		},
+		{
+			name: "resolved and outdated threads",
+			threads: []githubtest.ReviewThread{
+				{URL: threadURL(1)},
+				{URL: threadURL(2), Resolved: true},
+				{URL: threadURL(3), Outdated: true},
+				{URL: threadURL(4)},
+			},
+			want: []string{threadURL(1), threadURL(4)},
+		},
+		{
+			name:    "multiple pages",
+			threads: many,
+			want:    manyURLs,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newFakeClient(t)
+			srv.AddPR("cilium", "cilium", githubtest.PR{Commits: []githubtest.Commit{{SHA: "c1"}}})
+			for _, thread := range tt.threads {
+				srv.AddReviewThread("cilium", "cilium", 1, thread)
+			}
+
+			got, err := c.getUnresolvedThreads(context.Background(), "cilium", "cilium", 1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// Unknown PRs are reported.
+	_, c := newFakeClient(t)
+	_, err := c.getUnresolvedThreads(context.Background(), "cilium", "cilium", 2)
+	assert.ErrorContains(t, err, "unable to get review threads of PR 2")
+}
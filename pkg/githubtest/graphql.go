@@ -0,0 +1,227 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package githubtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	gh "github.com/google/go-github/v84/github"
+)
+
+// firstRegexp matches the page size of the paginated connection of a query.
+var firstRegexp = regexp.MustCompile(`\(first: ?(\d+)`)
+
+// graphQLFunc handles a GraphQL operation with the given query and variables.
+// s.mu is held while it runs. It returns the "data" of the response, or an
+// error message.
+type graphQLFunc func(query string, variables map[string]json.RawMessage) (interface{}, string)
+
+// graphQL serves the GraphQL operations used by the handlers. As the fake
+// does not parse GraphQL, an operation is recognized by the top-level field
+// it queries or mutates.
+func (s *Server) graphQL(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Query     string                     `json:"query"`
+		Variables map[string]json.RawMessage `json:"variables"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	operations := map[string]graphQLFunc{
+		"enablePullRequestAutoMerge(": s.enablePullRequestAutoMerge,
+		"reviewThreads(":              s.reviewThreads,
+		"mergeQueue(":                 s.mergeQueue,
+	}
+
+	var op graphQLFunc
+	for field, f := range operations {
+		if strings.Contains(body.Query, field) {
+			op = f
+		}
+	}
+
+	s.mu.Lock()
+	data, msg := interface{}(nil), "unknown operation"
+	if op != nil {
+		data, msg = op(body.Query, body.Variables)
+	} else {
+		s.unhandled = append(s.unhandled, "GraphQL "+body.Query)
+	}
+	b, _ := json.Marshal(data)
+	s.mu.Unlock()
+
+	if msg != "" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"errors": []map[string]string{{"message": msg}},
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]json.RawMessage{"data": b})
+}
+
+// pullRequestByNodeID returns the PR with the given node ID, or nil if it
+// does not exist. s.mu must be held.
+func (s *Server) pullRequestByNodeID(id string) *pullRequest {
+	for _, r := range s.repos {
+		for _, p := range r.prs {
+			if p.pr.GetNodeID() == id {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) enablePullRequestAutoMerge(_ string, variables map[string]json.RawMessage) (interface{}, string) {
+	var input struct {
+		PullRequestID   string `json:"pullRequestId"`
+		MergeMethod     string `json:"mergeMethod"`
+		ExpectedHeadOid string `json:"expectedHeadOid"`
+		CommitHeadline  string `json:"commitHeadline"`
+		CommitBody      string `json:"commitBody"`
+	}
+	if err := json.Unmarshal(variables["input"], &input); err != nil {
+		return nil, err.Error()
+	}
+	p := s.pullRequestByNodeID(input.PullRequestID)
+	switch {
+	case p == nil:
+		return nil, "Could not resolve to a PullRequest"
+	case input.ExpectedHeadOid != "" && input.ExpectedHeadOid != p.pr.GetHead().GetSHA():
+		return nil, "Head branch was modified"
+	}
+	p.pr.AutoMerge = &gh.PullRequestAutoMerge{
+		MergeMethod:   new(strings.ToLower(input.MergeMethod)),
+		CommitTitle:   new(input.CommitHeadline),
+		CommitMessage: new(input.CommitBody),
+	}
+	p.merge = &Merge{
+		AutoMerge: true,
+		Method:    strings.ToLower(input.MergeMethod),
+		Title:     input.CommitHeadline,
+		Body:      input.CommitBody,
+		SHA:       input.ExpectedHeadOid,
+	}
+	return map[string]interface{}{
+		"enablePullRequestAutoMerge": map[string]interface{}{
+			"pullRequest": map[string]string{"id": input.PullRequestID},
+		},
+	}, ""
+}
+
+// variable decodes the given variable into v. A missing or null variable
+// leaves v unchanged.
+func variable(variables map[string]json.RawMessage, name string, v interface{}) {
+	if raw, ok := variables[name]; ok {
+		_ = json.Unmarshal(raw, v)
+	}
+}
+
+// pullRequest returns the PR of the "owner", "name" and "number" variables,
+// or nil if it does not exist. s.mu must be held.
+func (s *Server) pullRequest(variables map[string]json.RawMessage) *pullRequest {
+	var (
+		owner, name string
+		number      int
+	)
+	variable(variables, "owner", &owner)
+	variable(variables, "name", &name)
+	variable(variables, "number", &number)
+	r, ok := s.repos[owner+"/"+name]
+	if !ok {
+		return nil
+	}
+	return r.prs[number]
+}
+
+// page returns the bounds of the page, of a connection with n nodes, that
+// the query asks for after the "cursor" variable, and the cursor of the end
+// of the page. Cursors are the indexes of the nodes.
+func page(query string, variables map[string]json.RawMessage, n int) (start, end int, endCursor string) {
+	var cursor string
+	variable(variables, "cursor", &cursor)
+	if cursor != "" {
+		start, _ = strconv.Atoi(cursor)
+	}
+	size := n
+	if m := firstRegexp.FindStringSubmatch(query); m != nil {
+		size, _ = strconv.Atoi(m[1])
+	}
+	end = min(start+size, n)
+	return start, end, strconv.Itoa(end)
+}
+
+func (s *Server) reviewThreads(query string, variables map[string]json.RawMessage) (interface{}, string) {
+	p := s.pullRequest(variables)
+	if p == nil {
+		return nil, "Could not resolve to a PullRequest"
+	}
+	start, end, endCursor := page(query, variables, len(p.threads))
+	nodes := []interface{}{}
+	for _, t := range p.threads[start:end] {
+		nodes = append(nodes, map[string]interface{}{
+			"isResolved": t.Resolved,
+			"isOutdated": t.Outdated,
+			"comments": map[string]interface{}{
+				"nodes": []map[string]string{{"url": t.URL}},
+			},
+		})
+	}
+	return map[string]interface{}{
+		"repository": map[string]interface{}{
+			"pullRequest": map[string]interface{}{
+				"reviewThreads": map[string]interface{}{
+					"nodes": nodes,
+					"pageInfo": map[string]interface{}{
+						"endCursor":   endCursor,
+						"hasNextPage": end < len(p.threads),
+					},
+				},
+			},
+		},
+	}, ""
+}
+
+func (s *Server) mergeQueue(query string, variables map[string]json.RawMessage) (interface{}, string) {
+	var owner, name, branch string
+	variable(variables, "owner", &owner)
+	variable(variables, "name", &name)
+	variable(variables, "branch", &branch)
+	r, ok := s.repos[owner+"/"+name]
+	if !ok {
+		return nil, "Could not resolve to a Repository"
+	}
+	entries := r.mergeQueues[branch]
+	start, end, _ := page(query, variables, len(entries))
+	nodes := []interface{}{}
+	for _, e := range entries[start:end] {
+		nodes = append(nodes, map[string]interface{}{
+			"headCommit":  map[string]string{"oid": e.HeadSHA},
+			"pullRequest": map[string]int{"number": e.Number},
+		})
+	}
+	return map[string]interface{}{
+		"repository": map[string]interface{}{
+			"mergeQueue": map[string]interface{}{
+				"entries": map[string]interface{}{"nodes": nodes},
+			},
+		},
+	}, ""
+}
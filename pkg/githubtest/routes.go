@@ -0,0 +1,655 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package githubtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+)
+
+// handlerFunc handles a request to a repository. s.mu is held while it runs.
+// It returns the status code and the value to encode as the JSON response.
+type handlerFunc func(r *repo, req *http.Request) (int, interface{})
+
+// notFound is the response to requests for resources that do not exist.
+var notFound = map[string]string{"message": "Not Found"}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	repoRoutes := map[string]handlerFunc{
+		"GET /contents/{path...}":                  s.getContents,
+		"GET /branches/{branch}/protection":        s.getBranchProtection,
+		"GET /rules/branches/{branch}":             s.getRulesForBranch,
+		"GET /collaborators/{user}/permission":     s.getPermission,
+		"GET /commits/{sha}":                       s.getCommit,
+		"GET /commits/{sha}/status":                s.getCombinedStatus,
+		"GET /commits/{sha}/check-runs":            s.listCheckRuns,
+		"GET /commits/{sha}/pulls":                 s.listPullsWithCommit,
+		"POST /check-runs":                         s.createCheckRun,
+		"PATCH /check-runs/{id}":                   s.updateCheckRun,
+		"GET /pulls":                               s.listPulls,
+		"GET /pulls/{number}":                      s.getPull,
+		"GET /pulls/{number}/commits":              s.listPullCommits,
+		"GET /pulls/{number}/files":                s.listPullFiles,
+		"GET /pulls/{number}/reviews":              s.listReviews,
+		"GET /pulls/{number}/requested_reviewers":  s.listRequestedReviewers,
+		"POST /pulls/{number}/requested_reviewers": s.requestReviewers,
+		"PUT /pulls/{number}/merge":                s.mergePull,
+		"GET /issues":                              s.listIssues,
+		"POST /issues":                             s.createIssue,
+		"PATCH /issues/{number}":                   s.editIssue,
+		"POST /issues/{number}/labels":             s.addLabels,
+		"DELETE /issues/{number}/labels/{name...}": s.removeLabel,
+		"GET /issues/{number}/comments":            s.listComments,
+		"POST /issues/{number}/comments":           s.createComment,
+		"PATCH /issues/comments/{id}":              s.editComment,
+	}
+	for route, h := range repoRoutes {
+		method, path, _ := strings.Cut(route, " ")
+		mux.HandleFunc(method+" /repos/{owner}/{repo}"+path, func(w http.ResponseWriter, req *http.Request) {
+			// The response is encoded while s.mu is held since it might
+			// refer to the state of the repository.
+			s.mu.Lock()
+			status, v := h(s.repo(req.PathValue("owner"), req.PathValue("repo")), req)
+			b, _ := json.Marshal(v)
+			s.mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			w.Write(b)
+		})
+	}
+	mux.HandleFunc("GET /repos/{owner}/{repo}/installation", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, &gh.Installation{ID: new(int64(1))})
+	})
+	mux.HandleFunc("GET /app/installations", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, []*gh.Installation{{ID: new(int64(1))}})
+	})
+	mux.HandleFunc("GET /installation/repositories", s.listInstallationRepos)
+	mux.HandleFunc("GET /rate_limit", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"resources": map[string]interface{}{
+				"core": &gh.Rate{
+					Limit:     5000,
+					Remaining: 5000,
+					Reset:     gh.Timestamp{Time: time.Now().Add(time.Hour)},
+				},
+			},
+		})
+	})
+	mux.HandleFunc("POST /app/installations/{id}/access_tokens", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusCreated, &gh.InstallationToken{
+			Token:     new("test-token"),
+			ExpiresAt: &gh.Timestamp{Time: time.Now().Add(time.Hour)},
+		})
+	})
+	mux.HandleFunc("GET /orgs/{org}/teams/{slug}/members", func(w http.ResponseWriter, req *http.Request) {
+		s.mu.Lock()
+		members, ok := s.teams[req.PathValue("org")+"/"+req.PathValue("slug")]
+		s.mu.Unlock()
+		if !ok {
+			writeJSON(w, http.StatusNotFound, notFound)
+			return
+		}
+		users := []*gh.User{}
+		for _, m := range members {
+			users = append(users, &gh.User{Login: new(m)})
+		}
+		writeJSON(w, http.StatusOK, users)
+	})
+	mux.HandleFunc("POST /graphql", s.graphQL)
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		s.mu.Lock()
+		s.unhandled = append(s.unhandled, req.Method+" "+req.URL.Path)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusNotFound, notFound)
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, req.Method+" "+req.URL.Path)
+		s.mu.Unlock()
+		mux.ServeHTTP(w, req)
+	})
+}
+
+// listInstallationRepos lists all repositories of the fake, which are all
+// part of the same installation.
+func (s *Server) listInstallationRepos(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	names := slices.Sorted(maps.Keys(s.repos))
+	s.mu.Unlock()
+	repos := []*gh.Repository{}
+	for _, name := range names {
+		owner, repoName, _ := strings.Cut(name, "/")
+		repos = append(repos, &gh.Repository{
+			Owner:    &gh.User{Login: new(owner)},
+			Name:     new(repoName),
+			FullName: new(name),
+		})
+	}
+	writeJSON(w, http.StatusOK, &gh.ListRepositories{
+		TotalCount:   new(len(repos)),
+		Repositories: repos,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func decode(req *http.Request, v interface{}) (int, interface{}) {
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		return http.StatusBadRequest, map[string]string{"message": err.Error()}
+	}
+	return 0, nil
+}
+
+// number returns the PR, or issue, number of the request, or 0 if invalid.
+func number(req *http.Request) int {
+	n, _ := strconv.Atoi(req.PathValue("number"))
+	return n
+}
+
+func (s *Server) getContents(r *repo, req *http.Request) (int, interface{}) {
+	path := req.PathValue("path")
+	content, ok := r.refFiles[req.URL.Query().Get("ref")][path]
+	if !ok {
+		content, ok = r.files[path]
+	}
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	return http.StatusOK, &gh.RepositoryContent{
+		Type:     new("file"),
+		Name:     new(path[strings.LastIndex(path, "/")+1:]),
+		Path:     new(path),
+		Encoding: new("base64"),
+		Content:  new(base64.StdEncoding.EncodeToString([]byte(content))),
+	}
+}
+
+func (s *Server) getBranchProtection(r *repo, req *http.Request) (int, interface{}) {
+	checks, ok := r.protections[req.PathValue("branch")]
+	if !ok {
+		return http.StatusNotFound, map[string]string{"message": "Branch not protected"}
+	}
+	return http.StatusOK, &gh.Protection{RequiredStatusChecks: checks}
+}
+
+func (s *Server) getRulesForBranch(r *repo, req *http.Request) (int, interface{}) {
+	rules := []interface{}{}
+	for i, checks := range r.rulesets[req.PathValue("branch")] {
+		rules = append(rules, map[string]interface{}{
+			"type":                "required_status_checks",
+			"ruleset_source_type": "Repository",
+			"ruleset_id":          i + 1,
+			"parameters": &gh.RequiredStatusChecksRuleParameters{
+				RequiredStatusChecks: checks,
+			},
+		})
+	}
+	return http.StatusOK, rules
+}
+
+func (s *Server) getPermission(r *repo, req *http.Request) (int, interface{}) {
+	user := req.PathValue("user")
+	perm, ok := r.permissions[user]
+	if !ok {
+		perm = "none"
+	}
+	return http.StatusOK, &gh.RepositoryPermissionLevel{
+		Permission: new(perm),
+		RoleName:   new(perm),
+		User:       &gh.User{Login: new(user)},
+	}
+}
+
+func (s *Server) getCommit(r *repo, req *http.Request) (int, interface{}) {
+	c, ok := r.commits[req.PathValue("sha")]
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	return http.StatusOK, c
+}
+
+func (s *Server) getCombinedStatus(r *repo, req *http.Request) (int, interface{}) {
+	sha := req.PathValue("sha")
+	statuses := r.statuses[sha]
+	state := "success"
+	for _, st := range statuses {
+		switch st.GetState() {
+		case "failure", "error":
+			state = "failure"
+		case "pending":
+			if state == "success" {
+				state = "pending"
+			}
+		}
+	}
+	if len(statuses) == 0 {
+		state = "pending"
+	}
+	return http.StatusOK, &gh.CombinedStatus{
+		State:      new(state),
+		SHA:        new(sha),
+		TotalCount: new(len(statuses)),
+		Statuses:   statuses,
+	}
+}
+
+// withPullRequests returns a copy of the check run with the open PRs whose
+// head is the SHA of the check run, as GitHub does.
+func (r *repo) withPullRequests(cr *gh.CheckRun) *gh.CheckRun {
+	c := *cr
+	c.PullRequests = nil
+	for _, p := range r.prs {
+		if p.pr.GetState() == "open" && p.pr.GetHead().GetSHA() == cr.GetHeadSHA() {
+			c.PullRequests = append(c.PullRequests, &gh.PullRequest{Number: p.pr.Number})
+		}
+	}
+	return &c
+}
+
+func (s *Server) listCheckRuns(r *repo, req *http.Request) (int, interface{}) {
+	sha := req.PathValue("sha")
+	name := req.URL.Query().Get("check_name")
+	appID := req.URL.Query().Get("app_id")
+	runs := []*gh.CheckRun{}
+	for _, cr := range r.checkRuns {
+		if cr.GetHeadSHA() == sha &&
+			(name == "" || cr.GetName() == name) &&
+			(appID == "" || strconv.FormatInt(cr.GetApp().GetID(), 10) == appID) {
+			runs = append(runs, r.withPullRequests(cr))
+		}
+	}
+	return http.StatusOK, &gh.ListCheckRunsResults{
+		Total:     new(len(runs)),
+		CheckRuns: runs,
+	}
+}
+
+func (s *Server) listPullsWithCommit(r *repo, req *http.Request) (int, interface{}) {
+	sha := req.PathValue("sha")
+	prs := []*gh.PullRequest{}
+	for _, p := range r.prs {
+		if slices.Contains(p.commits, sha) {
+			prs = append(prs, clonePR(p))
+		}
+	}
+	sortPRs(prs)
+	return http.StatusOK, prs
+}
+
+func (s *Server) createCheckRun(r *repo, req *http.Request) (int, interface{}) {
+	var opts gh.CreateCheckRunOptions
+	if status, v := decode(req, &opts); status != 0 {
+		return status, v
+	}
+	cr := &gh.CheckRun{
+		ID:          new(s.id()),
+		Name:        new(opts.Name),
+		HeadSHA:     new(opts.HeadSHA),
+		ExternalID:  opts.ExternalID,
+		Status:      opts.Status,
+		Conclusion:  opts.Conclusion,
+		CompletedAt: opts.CompletedAt,
+		Output:      opts.Output,
+		App:         &gh.App{ID: new(AppID), Slug: new(AppLogin)},
+	}
+	if cr.Status == nil {
+		cr.Status = new("queued")
+	}
+	r.checkRuns = append(r.checkRuns, cr)
+	return http.StatusCreated, r.withPullRequests(cr)
+}
+
+func (s *Server) updateCheckRun(r *repo, req *http.Request) (int, interface{}) {
+	id, _ := strconv.ParseInt(req.PathValue("id"), 10, 64)
+	i := slices.IndexFunc(r.checkRuns, func(cr *gh.CheckRun) bool { return cr.GetID() == id })
+	if i < 0 {
+		return http.StatusNotFound, notFound
+	}
+	var opts gh.UpdateCheckRunOptions
+	if status, v := decode(req, &opts); status != 0 {
+		return status, v
+	}
+	cr := *r.checkRuns[i]
+	cr.Name = new(opts.Name)
+	if opts.ExternalID != nil {
+		cr.ExternalID = opts.ExternalID
+	}
+	if opts.Status != nil {
+		cr.Status = opts.Status
+	}
+	if opts.Conclusion != nil {
+		cr.Conclusion = opts.Conclusion
+	}
+	if opts.CompletedAt != nil {
+		cr.CompletedAt = opts.CompletedAt
+	}
+	if opts.Output != nil {
+		cr.Output = opts.Output
+	}
+	r.checkRuns[i] = &cr
+	return http.StatusOK, r.withPullRequests(&cr)
+}
+
+func sortPRs(prs []*gh.PullRequest) {
+	slices.SortFunc(prs, func(a, b *gh.PullRequest) int { return a.GetNumber() - b.GetNumber() })
+}
+
+func (s *Server) listPulls(r *repo, req *http.Request) (int, interface{}) {
+	state := req.URL.Query().Get("state")
+	if state == "" {
+		state = "open"
+	}
+	base := req.URL.Query().Get("base")
+	prs := []*gh.PullRequest{}
+	for _, p := range r.prs {
+		if (state == "all" || p.pr.GetState() == state) && (base == "" || p.pr.GetBase().GetRef() == base) {
+			prs = append(prs, clonePR(p))
+		}
+	}
+	sortPRs(prs)
+	return http.StatusOK, prs
+}
+
+func (s *Server) getPull(r *repo, req *http.Request) (int, interface{}) {
+	p, ok := r.prs[number(req)]
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	return http.StatusOK, clonePR(p)
+}
+
+func (s *Server) listPullCommits(r *repo, req *http.Request) (int, interface{}) {
+	p, ok := r.prs[number(req)]
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	commits := []*gh.RepositoryCommit{}
+	for _, sha := range p.commits {
+		commits = append(commits, r.commits[sha])
+	}
+	return http.StatusOK, commits
+}
+
+func (s *Server) listPullFiles(r *repo, req *http.Request) (int, interface{}) {
+	p, ok := r.prs[number(req)]
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	files := []*gh.CommitFile{}
+	for _, f := range p.files {
+		files = append(files, &gh.CommitFile{Filename: new(f), Status: new("modified")})
+	}
+	return http.StatusOK, files
+}
+
+func (s *Server) listReviews(r *repo, req *http.Request) (int, interface{}) {
+	p, ok := r.prs[number(req)]
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	return http.StatusOK, append([]*gh.PullRequestReview{}, p.reviews...)
+}
+
+func (s *Server) listRequestedReviewers(r *repo, req *http.Request) (int, interface{}) {
+	p, ok := r.prs[number(req)]
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	reviewers := &gh.Reviewers{Users: []*gh.User{}, Teams: []*gh.Team{}}
+	for _, u := range p.requestedUsers {
+		reviewers.Users = append(reviewers.Users, &gh.User{Login: new(u)})
+	}
+	for _, t := range p.requestedTeams {
+		reviewers.Teams = append(reviewers.Teams, &gh.Team{Slug: new(t)})
+	}
+	return http.StatusOK, reviewers
+}
+
+func (s *Server) requestReviewers(r *repo, req *http.Request) (int, interface{}) {
+	p, ok := r.prs[number(req)]
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	var rr gh.ReviewersRequest
+	if status, v := decode(req, &rr); status != 0 {
+		return status, v
+	}
+	for _, u := range rr.Reviewers {
+		if !slices.Contains(p.requestedUsers, u) {
+			p.requestedUsers = append(p.requestedUsers, u)
+		}
+	}
+	for _, t := range rr.TeamReviewers {
+		if !slices.Contains(p.requestedTeams, t) {
+			p.requestedTeams = append(p.requestedTeams, t)
+		}
+	}
+	return http.StatusCreated, clonePR(p)
+}
+
+func (s *Server) mergePull(r *repo, req *http.Request) (int, interface{}) {
+	p, ok := r.prs[number(req)]
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	var body struct {
+		CommitTitle   string `json:"commit_title"`
+		CommitMessage string `json:"commit_message"`
+		MergeMethod   string `json:"merge_method"`
+		SHA           string `json:"sha"`
+	}
+	if status, v := decode(req, &body); status != 0 {
+		return status, v
+	}
+	switch {
+	case p.pr.GetState() != "open":
+		return http.StatusMethodNotAllowed, map[string]string{"message": "Pull Request is not mergeable"}
+	case body.SHA != "" && body.SHA != p.pr.GetHead().GetSHA():
+		return http.StatusConflict, map[string]string{"message": "Head branch was modified"}
+	}
+	p.pr.Merged = new(true)
+	p.pr.State = new("closed")
+	p.merge = &Merge{
+		Method: body.MergeMethod,
+		Title:  body.CommitTitle,
+		Body:   body.CommitMessage,
+		SHA:    body.SHA,
+	}
+	return http.StatusOK, &gh.PullRequestMergeResult{
+		SHA:     p.pr.GetHead().SHA,
+		Merged:  new(true),
+		Message: new("Pull Request successfully merged"),
+	}
+}
+
+// labels returns the labels of the given PR or issue, along with a function
+// that sets them, or false if neither exists.
+func (r *repo) labels(number int) ([]*gh.Label, func([]*gh.Label), bool) {
+	if p, ok := r.prs[number]; ok {
+		return p.pr.Labels, func(l []*gh.Label) { p.pr.Labels = l }, true
+	}
+	if issue, ok := r.issues[number]; ok {
+		return issue.Labels, func(l []*gh.Label) { issue.Labels = l }, true
+	}
+	return nil, nil, false
+}
+
+func hasLabels(labels []*gh.Label, names []string) bool {
+	for _, name := range names {
+		if !slices.ContainsFunc(labels, func(l *gh.Label) bool { return l.GetName() == name }) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) listIssues(r *repo, req *http.Request) (int, interface{}) {
+	q := req.URL.Query()
+	state := q.Get("state")
+	if state == "" {
+		state = "open"
+	}
+	var labels []string
+	if l := q.Get("labels"); l != "" {
+		labels = strings.Split(l, ",")
+	}
+	creator := q.Get("creator")
+	issues := []*gh.Issue{}
+	for _, issue := range r.issues {
+		if (state == "all" || issue.GetState() == state) &&
+			(creator == "" || issue.GetUser().GetLogin() == creator) &&
+			hasLabels(issue.Labels, labels) {
+			i := *issue
+			issues = append(issues, &i)
+		}
+	}
+	slices.SortFunc(issues, func(a, b *gh.Issue) int { return a.GetNumber() - b.GetNumber() })
+	return http.StatusOK, issues
+}
+
+func (s *Server) createIssue(r *repo, req *http.Request) (int, interface{}) {
+	var ir gh.IssueRequest
+	if status, v := decode(req, &ir); status != 0 {
+		return status, v
+	}
+	issue := &gh.Issue{
+		Title: ir.Title,
+		Body:  ir.Body,
+		User:  &gh.User{Login: new(AppLogin)},
+		State: new("open"),
+	}
+	if ir.Labels != nil {
+		issue.Labels = ghLabels(*ir.Labels)
+	}
+	r.addIssue(s.id(), issue)
+	i := *issue
+	return http.StatusCreated, &i
+}
+
+func (s *Server) editIssue(r *repo, req *http.Request) (int, interface{}) {
+	issue, ok := r.issues[number(req)]
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	var ir gh.IssueRequest
+	if status, v := decode(req, &ir); status != 0 {
+		return status, v
+	}
+	if ir.Title != nil {
+		issue.Title = ir.Title
+	}
+	if ir.Body != nil {
+		issue.Body = ir.Body
+	}
+	if ir.State != nil {
+		issue.State = ir.State
+	}
+	if ir.Labels != nil {
+		issue.Labels = ghLabels(*ir.Labels)
+	}
+	i := *issue
+	return http.StatusOK, &i
+}
+
+func (s *Server) addLabels(r *repo, req *http.Request) (int, interface{}) {
+	labels, set, ok := r.labels(number(req))
+	if !ok {
+		return http.StatusNotFound, notFound
+	}
+	var names []string
+	if status, v := decode(req, &names); status != 0 {
+		return status, v
+	}
+	for _, name := range names {
+		if !hasLabels(labels, []string{name}) {
+			labels = append(labels, &gh.Label{Name: new(name)})
+		}
+	}
+	set(labels)
+	return http.StatusOK, labels
+}
+
+func (s *Server) removeLabel(r *repo, req *http.Request) (int, interface{}) {
+	labels, set, ok := r.labels(number(req))
+	name := req.PathValue("name")
+	if !ok || !hasLabels(labels, []string{name}) {
+		return http.StatusNotFound, map[string]string{"message": "Label does not exist"}
+	}
+	labels = slices.DeleteFunc(slices.Clone(labels), func(l *gh.Label) bool { return l.GetName() == name })
+	set(labels)
+	return http.StatusOK, labels
+}
+
+func (s *Server) listComments(r *repo, req *http.Request) (int, interface{}) {
+	n := number(req)
+	if _, _, ok := r.labels(n); !ok {
+		return http.StatusNotFound, notFound
+	}
+	comments := []*gh.IssueComment{}
+	for _, c := range r.comments {
+		if c.number == n {
+			comments = append(comments, c.comment)
+		}
+	}
+	return http.StatusOK, comments
+}
+
+func (s *Server) createComment(r *repo, req *http.Request) (int, interface{}) {
+	n := number(req)
+	if _, _, ok := r.labels(n); !ok {
+		return http.StatusNotFound, notFound
+	}
+	var ic gh.IssueComment
+	if status, v := decode(req, &ic); status != 0 {
+		return status, v
+	}
+	c := &gh.IssueComment{
+		ID:        new(s.id()),
+		Body:      ic.Body,
+		User:      &gh.User{Login: new(AppLogin)},
+		CreatedAt: &gh.Timestamp{Time: time.Now()},
+	}
+	r.comments = append(r.comments, &comment{number: n, comment: c})
+	return http.StatusCreated, c
+}
+
+func (s *Server) editComment(r *repo, req *http.Request) (int, interface{}) {
+	id, _ := strconv.ParseInt(req.PathValue("id"), 10, 64)
+	i := slices.IndexFunc(r.comments, func(c *comment) bool { return c.comment.GetID() == id })
+	if i < 0 {
+		return http.StatusNotFound, notFound
+	}
+	var ic gh.IssueComment
+	if status, v := decode(req, &ic); status != 0 {
+		return status, v
+	}
+	c := *r.comments[i].comment
+	c.Body = ic.Body
+	r.comments[i].comment = &c
+	return http.StatusOK, &c
+}
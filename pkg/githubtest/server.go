@@ -0,0 +1,625 @@
+// Copyright 2026 Authors of Cilium
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package githubtest provides an in-memory fake of the GitHub REST API, served
+// by an httptest.Server, to test the handlers end to end.
+//
+// The fake models the repositories, files, branch protections, PRs, commits,
+// labels, reviews, commit statuses, check runs, issues and comments used by
+// the handlers, along with the few GraphQL operations listed in graphql.go.
+// Requests to endpoints that are not modelled get a 404 response, or a
+// GraphQL error, and are reported by Server.Unhandled.
+package githubtest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	gh "github.com/google/go-github/v84/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// AppLogin is the login of the author of the comments, issues and check runs
+// created through the fake.
+const AppLogin = "maintainers-little-helper[bot]"
+
+// AppID is the ID of the GitHub App that creates the check runs through the
+// fake.
+const AppID int64 = 1
+
+// Server is an in-memory fake of the GitHub REST API. All its methods are
+// safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	repos map[string]*repo
+	// teams maps "org/slug" to the logins of the members of the team.
+	teams     map[string][]string
+	nextID    int64
+	unhandled []string
+	// requests are all requests served, as "METHOD path".
+	requests []string
+}
+
+// repo is the state of a repository.
+type repo struct {
+	files map[string]string
+	// refFiles maps a ref to the files whose content differs at that ref.
+	refFiles map[string]map[string]string
+	// protections maps a branch to its required status checks.
+	protections map[string]*gh.RequiredStatusChecks
+	// rulesets maps a branch to the required status checks of each ruleset
+	// that applies to it.
+	rulesets map[string][][]*gh.RuleStatusCheck
+	// mergeQueues maps a branch to the entries of its merge queue.
+	mergeQueues map[string][]MergeQueueEntry
+	permissions map[string]string
+	commits     map[string]*gh.RepositoryCommit
+	statuses    map[string][]*gh.RepoStatus
+	checkRuns   []*gh.CheckRun
+	// PRs and issues share the same numbers, as in GitHub.
+	nextNumber int
+	prs        map[int]*pullRequest
+	issues     map[int]*gh.Issue
+	comments   []*comment
+}
+
+type pullRequest struct {
+	pr             *gh.PullRequest
+	commits        []string
+	files          []string
+	reviews        []*gh.PullRequestReview
+	requestedUsers []string
+	requestedTeams []string
+	merge          *Merge
+	threads        []ReviewThread
+}
+
+type comment struct {
+	number  int
+	comment *gh.IssueComment
+}
+
+// PR is a pull request to add to the fake.
+type PR struct {
+	Number int
+	Title  string
+	Body   string
+	Author string
+	// Base is the name of the base branch, "main" if empty.
+	Base    string
+	BaseSHA string
+	Draft   bool
+	Labels  []string
+	// Commits are the commits of the PR, the last one being its head.
+	Commits []Commit
+	// Files are the paths of the files changed by the PR.
+	Files []string
+}
+
+// Commit is a commit of a PR.
+type Commit struct {
+	SHA     string
+	Message string
+	Author  string
+	// Date is the committer date of the commit, the current time if zero.
+	Date time.Time
+}
+
+// Merge is how a PR was merged, or how GitHub's native auto-merge was
+// enabled in it.
+type Merge struct {
+	// AutoMerge is true if auto-merge was enabled instead of merging the PR.
+	AutoMerge bool
+	// Method is the merge method, e.g. "squash", in lower case.
+	Method string
+	Title  string
+	Body   string
+	// SHA is the head SHA the merge was pinned to.
+	SHA string
+}
+
+// ReviewThread is a review thread of a PR.
+type ReviewThread struct {
+	Resolved bool
+	Outdated bool
+	// URL is the URL of the first comment of the thread.
+	URL string
+}
+
+// MergeQueueEntry is an entry of a merge queue.
+type MergeQueueEntry struct {
+	// HeadSHA is the head SHA of the merge group of the entry.
+	HeadSHA string
+	Number  int
+}
+
+// Issue is an issue to add to the fake.
+type Issue struct {
+	Title  string
+	Body   string
+	Author string
+	Labels []string
+	Closed bool
+}
+
+// NewServer starts a fake GitHub API without any repository. It must be
+// closed once done.
+func NewServer() *Server {
+	s := &Server{
+		repos: map[string]*repo{},
+		teams: map[string][]string{},
+	}
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// Client returns a client of the REST API of the fake.
+func (s *Server) Client() *gh.Client {
+	c := gh.NewClient(nil)
+	c.BaseURL, _ = url.Parse(s.URL + "/")
+	return c
+}
+
+// V4Client returns a client of the GraphQL API of the fake.
+func (s *Server) V4Client() *githubv4.Client {
+	return githubv4.NewEnterpriseClient(s.URL+"/graphql", nil)
+}
+
+var (
+	privateKeyOnce sync.Once
+	privateKey     []byte
+)
+
+// PrivateKey returns a PEM encoded RSA private key that can be used to
+// authenticate as a GitHub App against the fake, which accepts any token.
+func PrivateKey() []byte {
+	privateKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+		privateKey = pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})
+	})
+	return privateKey
+}
+
+// repo returns the given repository, creating it if needed. s.mu must be
+// held.
+func (s *Server) repo(owner, repoName string) *repo {
+	key := owner + "/" + repoName
+	r, ok := s.repos[key]
+	if !ok {
+		r = &repo{
+			files:       map[string]string{},
+			refFiles:    map[string]map[string]string{},
+			protections: map[string]*gh.RequiredStatusChecks{},
+			rulesets:    map[string][][]*gh.RuleStatusCheck{},
+			mergeQueues: map[string][]MergeQueueEntry{},
+			permissions: map[string]string{},
+			commits:     map[string]*gh.RepositoryCommit{},
+			statuses:    map[string][]*gh.RepoStatus{},
+			prs:         map[int]*pullRequest{},
+			issues:      map[int]*gh.Issue{},
+		}
+		s.repos[key] = r
+	}
+	return r
+}
+
+// id returns a new unique ID. s.mu must be held.
+func (s *Server) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// SetFile sets the content of the file at the given path. Files have the same
+// content at all refs, unless set at a ref with SetFileAt.
+func (s *Server) SetFile(owner, repoName, path, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repo(owner, repoName).files[path] = content
+}
+
+// SetFileAt sets the content of the file at the given path and ref, e.g. a
+// branch name or a SHA.
+func (s *Server) SetFileAt(owner, repoName, ref, path, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.repo(owner, repoName)
+	if r.refFiles[ref] == nil {
+		r.refFiles[ref] = map[string]string{}
+	}
+	r.refFiles[ref][path] = content
+}
+
+// SetBranchProtection protects the given branch with the given required
+// status checks, which can be provided by any app.
+func (s *Server) SetBranchProtection(owner, repoName, branch string, requiredChecks ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repo(owner, repoName).protections[branch] = &gh.RequiredStatusChecks{Contexts: &requiredChecks}
+}
+
+// SetBranchProtectionChecks protects the given branch with the given required
+// status checks, which can be pinned to an app.
+func (s *Server) SetBranchProtectionChecks(owner, repoName, branch string, requiredChecks ...*gh.RequiredStatusCheck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repo(owner, repoName).protections[branch] = &gh.RequiredStatusChecks{Checks: &requiredChecks}
+}
+
+// AddRuleset adds a ruleset, that requires the given status checks, to the
+// given branch.
+func (s *Server) AddRuleset(owner, repoName, branch string, requiredChecks ...*gh.RuleStatusCheck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.repo(owner, repoName)
+	r.rulesets[branch] = append(r.rulesets[branch], requiredChecks)
+}
+
+// SetMergeQueue sets the entries of the merge queue of the given branch.
+func (s *Server) SetMergeQueue(owner, repoName, branch string, entries ...MergeQueueEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repo(owner, repoName).mergeQueues[branch] = entries
+}
+
+// SetPermission sets the permission, e.g. "write", of the given user in the
+// repository.
+func (s *Server) SetPermission(owner, repoName, user, permission string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repo(owner, repoName).permissions[user] = permission
+}
+
+// SetTeamMembers sets the members of the team "org/slug".
+func (s *Server) SetTeamMembers(org, slug string, members ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams[org+"/"+slug] = members
+}
+
+// AddPR adds an open PR to the repository and returns it. A number is
+// assigned to the PR if it does not have one.
+func (s *Server) AddPR(owner, repoName string, pr PR) *gh.PullRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.repo(owner, repoName)
+	if pr.Number == 0 {
+		r.nextNumber++
+		pr.Number = r.nextNumber
+	}
+	r.nextNumber = max(r.nextNumber, pr.Number)
+	if pr.Base == "" {
+		pr.Base = "main"
+	}
+
+	ghRepo := &gh.Repository{
+		Name:  new(repoName),
+		Owner: &gh.User{Login: new(owner)},
+	}
+	p := &pullRequest{
+		pr: &gh.PullRequest{
+			ID:     new(s.id()),
+			NodeID: new(fmt.Sprintf("PR_%s_%d", repoName, pr.Number)),
+			Number: new(pr.Number),
+			State:  new("open"),
+			Title:  new(pr.Title),
+			Body:   new(pr.Body),
+			Draft:  new(pr.Draft),
+			User:   &gh.User{Login: new(pr.Author)},
+			Labels: ghLabels(pr.Labels),
+			Base: &gh.PullRequestBranch{
+				Ref:  new(pr.Base),
+				SHA:  new(pr.BaseSHA),
+				Repo: ghRepo,
+			},
+			Head: &gh.PullRequestBranch{Repo: ghRepo},
+		},
+		files: pr.Files,
+	}
+	for _, c := range pr.Commits {
+		date := c.Date
+		if date.IsZero() {
+			date = time.Now()
+		}
+		r.commits[c.SHA] = &gh.RepositoryCommit{
+			SHA:    new(c.SHA),
+			Author: &gh.User{Login: new(c.Author)},
+			Commit: &gh.Commit{
+				SHA:       new(c.SHA),
+				Message:   new(c.Message),
+				Author:    &gh.CommitAuthor{Login: new(c.Author), Date: &gh.Timestamp{Time: date}},
+				Committer: &gh.CommitAuthor{Date: &gh.Timestamp{Time: date}},
+			},
+		}
+		p.commits = append(p.commits, c.SHA)
+		p.pr.Head.SHA = new(c.SHA)
+	}
+	r.prs[pr.Number] = p
+	return clonePR(p)
+}
+
+// AddReview adds a review with the given state, e.g. "APPROVED", of the given
+// commit to the PR. The review is removed from the requested reviewers.
+func (s *Server) AddReview(owner, repoName string, number int, user, state, commitID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.repo(owner, repoName).prs[number]
+	if !ok {
+		panic("unknown PR")
+	}
+	p.reviews = append(p.reviews, &gh.PullRequestReview{
+		ID:          new(s.id()),
+		User:        &gh.User{Login: new(user)},
+		State:       new(state),
+		CommitID:    new(commitID),
+		SubmittedAt: &gh.Timestamp{Time: time.Now()},
+	})
+	p.requestedUsers = slices.DeleteFunc(p.requestedUsers, func(u string) bool { return u == user })
+}
+
+// RequestReviews requests reviews from the given users and teams.
+func (s *Server) RequestReviews(owner, repoName string, number int, users, teams []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.repo(owner, repoName).prs[number]
+	if !ok {
+		panic("unknown PR")
+	}
+	p.requestedUsers = append(p.requestedUsers, users...)
+	p.requestedTeams = append(p.requestedTeams, teams...)
+}
+
+// AddReviewThread adds a review thread to the given PR.
+func (s *Server) AddReviewThread(owner, repoName string, number int, thread ReviewThread) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.repo(owner, repoName).prs[number]
+	if !ok {
+		panic("unknown PR")
+	}
+	p.threads = append(p.threads, thread)
+}
+
+// SetStatus sets the state, e.g. "success", of the commit status of the given
+// SHA and context.
+func (s *Server) SetStatus(owner, repoName, sha, context, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.repo(owner, repoName)
+	r.statuses[sha] = slices.DeleteFunc(r.statuses[sha], func(st *gh.RepoStatus) bool {
+		return st.GetContext() == context
+	})
+	r.statuses[sha] = append(r.statuses[sha], &gh.RepoStatus{
+		ID:      new(s.id()),
+		Context: new(context),
+		State:   new(state),
+	})
+}
+
+// AddCheckRun adds a check run, created by the given app, with the given
+// status and conclusion, to the given SHA and returns its ID.
+func (s *Server) AddCheckRun(owner, repoName, sha string, appID int64, name, status, conclusion string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.repo(owner, repoName)
+	cr := &gh.CheckRun{
+		ID:      new(s.id()),
+		Name:    new(name),
+		HeadSHA: new(sha),
+		Status:  new(status),
+		App:     &gh.App{ID: new(appID)},
+	}
+	if conclusion != "" {
+		cr.Conclusion = new(conclusion)
+	}
+	r.checkRuns = append(r.checkRuns, cr)
+	return cr.GetID()
+}
+
+// AddIssue adds an issue to the repository and returns its number.
+func (s *Server) AddIssue(owner, repoName string, issue Issue) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := "open"
+	if issue.Closed {
+		state = "closed"
+	}
+	return s.repo(owner, repoName).addIssue(s.id(), &gh.Issue{
+		Title:  new(issue.Title),
+		Body:   new(issue.Body),
+		User:   &gh.User{Login: new(issue.Author)},
+		Labels: ghLabels(issue.Labels),
+		State:  new(state),
+	})
+}
+
+// addIssue assigns the next number of the repository to the given issue.
+func (r *repo) addIssue(id int64, issue *gh.Issue) int {
+	r.nextNumber++
+	issue.ID = new(id)
+	issue.Number = new(r.nextNumber)
+	r.issues[r.nextNumber] = issue
+	return r.nextNumber
+}
+
+// PullRequest returns the current state of the given PR, e.g. to build the
+// payload of a webhook event, or nil if it does not exist.
+func (s *Server) PullRequest(owner, repoName string, number int) *gh.PullRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.repo(owner, repoName).prs[number]
+	if !ok {
+		return nil
+	}
+	return clonePR(p)
+}
+
+// Labels returns the sorted labels of the given PR or issue.
+func (s *Server) Labels(owner, repoName string, number int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.repo(owner, repoName)
+	var labels []*gh.Label
+	if p, ok := r.prs[number]; ok {
+		labels = p.pr.Labels
+	} else if issue, ok := r.issues[number]; ok {
+		labels = issue.Labels
+	}
+	var names []string
+	for _, l := range labels {
+		names = append(names, l.GetName())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Comments returns the bodies of the comments of the given PR or issue, in
+// the order they were created.
+func (s *Server) Comments(owner, repoName string, number int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var bodies []string
+	for _, c := range s.repo(owner, repoName).comments {
+		if c.number == number {
+			bodies = append(bodies, c.comment.GetBody())
+		}
+	}
+	return bodies
+}
+
+// CheckRun returns the last check run with the given name of the given SHA,
+// or nil if there is none.
+func (s *Server) CheckRun(owner, repoName, sha, name string) *gh.CheckRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.repo(owner, repoName)
+	for _, cr := range slices.Backward(r.checkRuns) {
+		if cr.GetHeadSHA() == sha && cr.GetName() == name {
+			c := *cr
+			return &c
+		}
+	}
+	return nil
+}
+
+// CheckRuns returns the check runs of the given SHA in the order they were
+// created.
+func (s *Server) CheckRuns(owner, repoName, sha string) []*gh.CheckRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var runs []*gh.CheckRun
+	for _, cr := range s.repo(owner, repoName).checkRuns {
+		if cr.GetHeadSHA() == sha {
+			c := *cr
+			runs = append(runs, &c)
+		}
+	}
+	return runs
+}
+
+// RequestedReviewers returns the users and teams whose review of the given PR
+// is requested.
+func (s *Server) RequestedReviewers(owner, repoName string, number int) (users, teams []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.repo(owner, repoName).prs[number]
+	if !ok {
+		return nil, nil
+	}
+	return slices.Clone(p.requestedUsers), slices.Clone(p.requestedTeams)
+}
+
+// Merged returns true if the given PR was merged.
+func (s *Server) Merged(owner, repoName string, number int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.repo(owner, repoName).prs[number]
+	return ok && p.pr.GetMerged()
+}
+
+// Merge returns how the given PR was merged, or how auto-merge was enabled in
+// it, or nil if neither happened.
+func (s *Server) Merge(owner, repoName string, number int) *Merge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.repo(owner, repoName).prs[number]
+	if !ok || p.merge == nil {
+		return nil
+	}
+	m := *p.merge
+	return &m
+}
+
+// Issues returns the issues of the repository, excluding PRs, sorted by
+// number.
+func (s *Server) Issues(owner, repoName string) []*gh.Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var issues []*gh.Issue
+	for _, issue := range s.repo(owner, repoName).issues {
+		i := *issue
+		issues = append(issues, &i)
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].GetNumber() < issues[j].GetNumber() })
+	return issues
+}
+
+// Requests returns all requests served by the fake, as "METHOD path", in
+// order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Unhandled returns the requests, as "METHOD path", to endpoints that are not
+// modelled by the fake.
+func (s *Server) Unhandled() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.unhandled)
+}
+
+func ghLabels(names []string) []*gh.Label {
+	var labels []*gh.Label
+	for _, name := range names {
+		labels = append(labels, &gh.Label{Name: new(name)})
+	}
+	return labels
+}
+
+// clonePR returns a copy of the PR that is not changed by later requests.
+func clonePR(p *pullRequest) *gh.PullRequest {
+	pr := *p.pr
+	pr.Labels = slices.Clone(pr.Labels)
+	base, head := *pr.Base, *pr.Head
+	pr.Base, pr.Head = &base, &head
+	return &pr
+}